@@ -0,0 +1,147 @@
+package camera
+
+import (
+	"unsafe"
+	"vec3/framebuffer"
+	"vec3/vec3"
+)
+
+// firstHit records what a camera ray saw at its first intersection, for the AOVs.
+type firstHit struct {
+	isHit  bool
+	rec    vec3.Hit
+	albedo vec3.Color
+}
+
+// aovBuffers holds the AOV framebuffers of one render, along with the first material
+// seen through each pixel, which is only numbered once the whole image is done.
+type aovBuffers struct {
+	depth, normal, albedo, position, materialID, objectID *framebuffer.Framebuffer
+	materials                                             []vec3.Material
+}
+
+// SetAOVs selects the extra buffers Render produces alongside the beauty image.
+func (cam *Camera) SetAOVs(aovs ...framebuffer.AOV) {
+	cam.aovs = aovs
+}
+
+func (cam *Camera) newAOVBuffers(fb *framebuffer.Framebuffer) *aovBuffers {
+	if len(cam.aovs) == 0 {
+		return nil
+	}
+	bufs := &aovBuffers{}
+	for _, a := range cam.aovs {
+		buf := fb.EnableAOV(a)
+		switch a {
+		case framebuffer.AOVDepth:
+			bufs.depth = buf
+		case framebuffer.AOVNormal:
+			bufs.normal = buf
+		case framebuffer.AOVAlbedo:
+			bufs.albedo = buf
+		case framebuffer.AOVPosition:
+			bufs.position = buf
+		case framebuffer.AOVMaterialID:
+			bufs.materialID = buf
+			bufs.materials = make([]vec3.Material, fb.Width()*fb.Height())
+		case framebuffer.AOVObjectID:
+			bufs.objectID = buf
+		}
+	}
+	return bufs
+}
+
+func (cam *Camera) accumulateAOVs(bufs *aovBuffers, i int, j int, sample int, first firstHit) {
+	// Averaged AOVs take every sample, misses included, so edges are coverage weighted.
+	// Identifiers cannot be averaged and come from the first sample alone.
+	var zero vec3.Color
+	if !first.isHit {
+		if bufs.depth != nil {
+			bufs.depth.Accumulate(i, j, zero, 1)
+		}
+		if bufs.normal != nil {
+			bufs.normal.Accumulate(i, j, zero, 1)
+		}
+		if bufs.albedo != nil {
+			bufs.albedo.Accumulate(i, j, first.albedo, 1)
+		}
+		if bufs.position != nil {
+			bufs.position.Accumulate(i, j, zero, 1)
+		}
+		if sample == 0 && bufs.objectID != nil {
+			bufs.objectID.Accumulate(i, j, zero, 1)
+		}
+		return
+	}
+
+	p := first.rec.P()
+	if bufs.depth != nil {
+		d := vec3.Dot(p.Sub(cam.center.Vec3), cam.w.Inv())
+		bufs.depth.Accumulate(i, j, vec3.NewColor(d, d, d), 1)
+	}
+	if bufs.normal != nil {
+		n := first.rec.Normal()
+		bufs.normal.Accumulate(i, j, vec3.NewColor(n.X(), n.Y(), n.Z()), 1)
+	}
+	if bufs.albedo != nil {
+		bufs.albedo.Accumulate(i, j, first.albedo, 1)
+	}
+	if bufs.position != nil {
+		bufs.position.Accumulate(i, j, vec3.NewColor(p.X(), p.Y(), p.Z()), 1)
+	}
+	if sample == 0 {
+		if bufs.materials != nil {
+			bufs.materials[j*cam.imageWidth+i] = first.rec.Material()
+		}
+		if bufs.objectID != nil {
+			id := float64(first.rec.ObjectID() + 1)
+			bufs.objectID.Accumulate(i, j, vec3.NewColor(id, id, id), 1)
+		}
+	}
+}
+
+func (cam *Camera) resolveMaterialIDs(bufs *aovBuffers) {
+	// Number the materials in the order they first appear in scanline order, which does
+	// not depend on how the tiles were scheduled.
+	if bufs == nil || bufs.materials == nil {
+		return
+	}
+	ids := materialIDs{ids: map[materialKey]int{}}
+	for j := 0; j < cam.imageHeight; j++ {
+		for i := 0; i < cam.imageWidth; i++ {
+			id := 0
+			if mat := bufs.materials[j*cam.imageWidth+i]; mat != nil {
+				id = ids.id(mat)
+			}
+			v := float64(id)
+			bufs.materialID.Accumulate(i, j, vec3.NewColor(v, v, v), 1)
+		}
+	}
+}
+
+// materialIDs numbers materials from 1 in the order they are first asked for. A
+// material is told apart by its identity as an interface value rather than compared by
+// value, so any material can be numbered, and in constant time. Objects given the same
+// Material interface value share an ID, while each conversion of a concrete material to
+// a Material makes a new one, even from equal values.
+type materialIDs struct {
+	ids   map[materialKey]int
+	count int
+}
+
+// materialKey is the dynamic type and data word of an interface value. The data word
+// points to the value the interface holds, or is that value for pointers.
+type materialKey struct {
+	typ, data unsafe.Pointer
+}
+
+func (m *materialIDs) id(mat vec3.Material) int {
+	key := *(*materialKey)(unsafe.Pointer(&mat))
+	id, ok := m.ids[key]
+	if !ok {
+		m.count++
+		id = m.count
+		m.ids[key] = id
+	}
+	return id
+}
@@ -0,0 +1,27 @@
+package camera
+
+import (
+	"testing"
+	"vec3/vec3"
+)
+
+// paletteMaterial holds a slice, so it cannot be compared or used as a map key.
+type paletteMaterial struct {
+	vec3.Lambertian
+	palette []vec3.Color
+}
+
+func TestMaterialIDs(t *testing.T) {
+	var shared vec3.Material = vec3.NewLambertian(vec3.NewColor(0.5, 0.5, 0.5))
+	var palette vec3.Material = paletteMaterial{palette: []vec3.Color{vec3.NewColor(1, 0, 0)}}
+	var other vec3.Material = vec3.NewLambertian(vec3.NewColor(0.5, 0.5, 0.5))
+
+	ids := materialIDs{ids: map[materialKey]int{}}
+	got := []int{ids.id(shared), ids.id(palette), ids.id(shared), ids.id(other), ids.id(palette)}
+	want := []int{1, 2, 1, 3, 2}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ids = %v, want %v", got, want)
+		}
+	}
+}
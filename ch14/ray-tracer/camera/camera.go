@@ -1,35 +1,50 @@
 package camera
 
 import (
-	"fmt"
 	"log"
 	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"vec3/framebuffer"
 	"vec3/vec3"
 )
 
 type Camera struct {
-	aspectRatio     float64     // Ratio of image width over height
-	imageWidth      int         // Rendered image width in pixel count
-	samplesPerPixel int         // Count of random samples for each pixel
-	maxDepth        int         // Maximum number of ray bounces into scene
-	imageHeight     int         // Rendered image height
-	vfov            float64     // Vertical view angle (field of view)
-	lookFrom        vec3.Point3 // Point camera is looking from
-	lookAt          vec3.Point3 // Point camera is looking at
-	vup             vec3.Vec3   // Camera-relative "up" direction
-	defocusAngle    float64     // Variation angle of rays through each pixel
-	focusDist       float64     // Distance from camera lookFrom point to plane of perfect focus
-	center          vec3.Point3 // Camera center
-	pixel00Loc      vec3.Point3 // Location of pixel 0, 0
-	pixelDeltaU     vec3.Vec3   // Offset to pixel to the right
-	pixelDeltaV     vec3.Vec3   // Offset to pixel below
-	u, v, w         vec3.Vec3   // Camera frame basis vectors
-	defocusDiskU    vec3.Vec3   // Defocus disk horizontal radius
-	defocusDiskV    vec3.Vec3   // Defocus disk vertical radius
+	aspectRatio     float64           // Ratio of image width over height
+	imageWidth      int               // Rendered image width in pixel count
+	samplesPerPixel int               // Count of random samples for each pixel
+	maxDepth        int               // Maximum number of ray bounces into scene
+	imageHeight     int               // Rendered image height
+	vfov            float64           // Vertical view angle (field of view)
+	lookFrom        vec3.Point3       // Point camera is looking from
+	lookAt          vec3.Point3       // Point camera is looking at
+	vup             vec3.Vec3         // Camera-relative "up" direction
+	defocusAngle    float64           // Variation angle of rays through each pixel
+	focusDist       float64           // Distance from camera lookFrom point to plane of perfect focus
+	center          vec3.Point3       // Camera center
+	pixel00Loc      vec3.Point3       // Location of pixel 0, 0
+	pixelDeltaU     vec3.Vec3         // Offset to pixel to the right
+	pixelDeltaV     vec3.Vec3         // Offset to pixel below
+	u, v, w         vec3.Vec3         // Camera frame basis vectors
+	defocusDiskU    vec3.Vec3         // Defocus disk horizontal radius
+	defocusDiskV    vec3.Vec3         // Defocus disk vertical radius
+	workers         int               // Number of goroutines rendering tiles, 0 means one per CPU
+	tileSize        int               // Width and height of a square render tile in pixels
+	seed            uint64            // Seed for the per-pixel, per-sample random streams
+	toneMapper      vec3.ToneMapper   // Operator used when the rendered image is encoded for display
+	aovs            []framebuffer.AOV // Extra buffers rendered alongside the beauty image
+	shutterOpen     float64           // Time at which the shutter opens
+	shutterClose    float64           // Time at which the shutter closes
+}
+
+// tile is a rectangle of pixels, [x0, x1) by [y0, y1), rendered by a single worker.
+type tile struct {
+	x0, y0, x1, y1 int
 }
 
 func NewCamera() Camera {
-	c := Camera{aspectRatio: 1.0, imageWidth: 100, samplesPerPixel: 10, maxDepth: 10, vfov: 90, defocusAngle: 0, focusDist: 10}
+	c := Camera{aspectRatio: 1.0, imageWidth: 100, samplesPerPixel: 10, maxDepth: 10, vfov: 90, defocusAngle: 0, focusDist: 10, tileSize: 32}
 	return c
 }
 
@@ -73,24 +88,116 @@ func (cam *Camera) SetFocusDistance(dist float64) {
 	cam.focusDist = dist
 }
 
-func (cam *Camera) Render(world vec3.Hittable) {
+// SetWorkers sets the number of goroutines used by Render. A value of zero or less
+// uses one worker per CPU.
+func (cam *Camera) SetWorkers(workers int) {
+	cam.workers = workers
+}
+
+func (cam *Camera) SetTileSize(size int) {
+	cam.tileSize = size
+}
+
+// SetSeed sets the seed of the sample random streams. The same scene and seed render
+// to the same image regardless of the number of workers.
+func (cam *Camera) SetSeed(seed uint64) {
+	cam.seed = seed
+}
+
+// SetToneMapper selects the operator that maps the rendered radiance to display values.
+// A nil operator keeps the default gamma transform.
+func (cam *Camera) SetToneMapper(tm vec3.ToneMapper) {
+	cam.toneMapper = tm
+}
+
+// SetShutter sets the interval over which ray times are sampled, for motion blur. The
+// shutter is open only at time 0 by default, which renders moving objects sharp at
+// their starting positions.
+func (cam *Camera) SetShutter(open float64, close float64) {
+	cam.shutterOpen = open
+	cam.shutterClose = close
+}
+
+// Render renders the world into a new framebuffer of linear radiance. Encode the
+// framebuffer to write it out.
+func (cam *Camera) Render(world vec3.Hittable) *framebuffer.Framebuffer {
 	cam.initialize()
 
-	fmt.Printf("P3\n%d %d\n255\n", cam.imageWidth, cam.imageHeight)
+	// Render the tiles in parallel; each worker accumulates into its own pixels.
+	fb := framebuffer.New(cam.imageWidth, cam.imageHeight)
+	fb.SetToneMapper(cam.toneMapper)
+	aovs := cam.newAOVBuffers(fb)
+	tiles := cam.tiles()
+	queue := make(chan tile)
+	remaining := int32(len(tiles))
+
+	var wg sync.WaitGroup
+	for w := 0; w < cam.workerCount(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range queue {
+				cam.renderTile(t, world, fb, aovs)
+				log.Printf("\rTiles remaining: %d", atomic.AddInt32(&remaining, -1))
+			}
+		}()
+	}
+	for _, t := range tiles {
+		queue <- t
+	}
+	close(queue)
+	wg.Wait()
+	cam.resolveMaterialIDs(aovs)
 
-	for j := 0; j < cam.imageHeight; j++ {
-		log.Printf("\rScanlines remaining: %d", cam.imageHeight-j)
-		for i := 0; i < cam.imageWidth; i++ {
+	log.Println("\rDone.")
+	return fb
+}
+
+func (cam *Camera) renderTile(t tile, world vec3.Hittable, fb *framebuffer.Framebuffer, aovs *aovBuffers) {
+	// Every sample is drawn from its own stream, seeded from (seed, pixel, sample), so
+	// the result does not depend on which worker renders the tile.
+	var rng vec3.PCG
+	for j := t.y0; j < t.y1; j++ {
+		for i := t.x0; i < t.x1; i++ {
 			pixelColor := vec3.NewColor(0, 0, 0)
 			for sample := 0; sample < cam.samplesPerPixel; sample++ {
-				r := cam.GetRay(i, j)
-				rc := rayColor(r, cam.maxDepth, world)
+				rng.SeedSample(cam.seed, j*cam.imageWidth+i, sample)
+				r := cam.GetRay(i, j, &rng)
+				if aovs == nil {
+					rc := rayColor(r, cam.maxDepth, world, &rng, nil)
+					pixelColor.Vec3 = pixelColor.Vec3.Add(rc.Vec3)
+					continue
+				}
+				var first firstHit
+				rc := rayColor(r, cam.maxDepth, world, &rng, &first)
 				pixelColor.Vec3 = pixelColor.Vec3.Add(rc.Vec3)
+				cam.accumulateAOVs(aovs, i, j, sample, first)
 			}
-			fmt.Print(pixelColor.Write(cam.samplesPerPixel))
+			fb.Accumulate(i, j, pixelColor, cam.samplesPerPixel)
 		}
 	}
-	log.Println("\rDone.")
+}
+
+func (cam *Camera) tiles() []tile {
+	// Split the image into square tiles, clipped at the right and bottom edges.
+	size := cam.tileSize
+	if size < 1 {
+		size = 1
+	}
+	var tiles []tile
+	for y := 0; y < cam.imageHeight; y += size {
+		for x := 0; x < cam.imageWidth; x += size {
+			tiles = append(tiles, tile{x, y, min(x+size, cam.imageWidth), min(y+size, cam.imageHeight)})
+		}
+	}
+	return tiles
+}
+
+func (cam *Camera) workerCount() int {
+	if cam.workers > 0 {
+		return cam.workers
+	}
+	return runtime.NumCPU()
 }
 
 func (cam *Camera) initialize() {
@@ -133,7 +240,7 @@ func (cam *Camera) initialize() {
 	cam.defocusDiskV = cam.v.Mul(defocusRadius)
 }
 
-func (cam *Camera) GetRay(i int, j int) vec3.Ray {
+func (cam *Camera) GetRay(i int, j int, rng vec3.RNG) vec3.Ray {
 	// Get a randomly sampled camera ray for the pixel at location i,j, originating from
 	// the camera defocus disk.
 
@@ -142,46 +249,57 @@ func (cam *Camera) GetRay(i int, j int) vec3.Ray {
 			Mul(float64(i))).
 		Add(cam.pixelDeltaV.
 			Mul(float64(j)))
-	pixelSample := pixelCenter.Add(cam.pixelSampleSquare())
+	pixelSample := pixelCenter.Add(cam.pixelSampleSquare(rng))
 
 	var rayOrigin vec3.Point3
 	if cam.defocusAngle <= 0 {
 		rayOrigin = cam.center
 	} else {
-		rayOrigin = cam.defocusDiskSample()
+		rayOrigin = cam.defocusDiskSample(rng)
 	}
 	rayDirection := pixelSample.Sub(rayOrigin.Vec3)
 
-	return vec3.NewRay(rayOrigin, rayDirection)
+	// Draw a time only for an open shutter, so still images keep their sample streams.
+	rayTime := cam.shutterOpen
+	if cam.shutterClose > cam.shutterOpen {
+		rayTime = vec3.RandomInRange(rng, cam.shutterOpen, cam.shutterClose)
+	}
+
+	return vec3.NewRayAtTime(rayOrigin, rayDirection, rayTime)
 }
 
-func (cam *Camera) defocusDiskSample() vec3.Point3 {
+func (cam *Camera) defocusDiskSample(rng vec3.RNG) vec3.Point3 {
 	// Returns a random point in the camera defocus disk.
-	p := vec3.RandomInUnitDisk()
+	p := vec3.RandomInUnitDisk(rng)
 	//center + (p[0] * defocus_disk_u) + (p[1] * defocus_disk_v)
 
 	result := cam.center.Add(cam.defocusDiskU.Mul(p.X())).Add(cam.defocusDiskV.Mul(p.Y()))
 	return vec3.NewPoint3(result.X(), result.Y(), result.Z())
 }
 
-func (cam *Camera) pixelSampleSquare() vec3.Vec3 {
+func (cam *Camera) pixelSampleSquare(rng vec3.RNG) vec3.Vec3 {
 	// Returns a random point in the square surrounding a pixel at the origin.
-	px := -0.5 + vec3.Random()
-	py := -0.5 + vec3.Random()
+	px := -0.5 + vec3.Random(rng)
+	py := -0.5 + vec3.Random(rng)
 	return cam.pixelDeltaU.Mul(px).Add(cam.pixelDeltaV.Mul(py))
 
 }
 
-func rayColor(r vec3.Ray, depth int, world vec3.Hittable) vec3.Color {
+// rayColor returns the radiance along r. When first is not nil it is filled in with the
+// first intersection of r.
+func rayColor(r vec3.Ray, depth int, world vec3.Hittable, rng vec3.RNG, first *firstHit) vec3.Color {
 	//If we've exceeded the ray bounce limit, no more light is gathered.
 	if depth <= 0 {
 		return vec3.NewColor(0, 0, 0)
 	}
-	isHit, hitRec := world.Hit(r, vec3.NewInterval(0.001, math.Inf(1)))
+	isHit, hitRec := world.Hit(r, vec3.NewInterval(0.001, math.Inf(1)), rng)
 	if isHit {
-		ok, scattered, attenuation := hitRec.Material().Scatter(r, hitRec)
+		ok, scattered, attenuation := hitRec.Material().Scatter(r, hitRec, rng)
+		if first != nil {
+			*first = firstHit{isHit: true, rec: hitRec, albedo: attenuation}
+		}
 		if ok {
-			tempV := vec3.MultVec(rayColor(scattered, depth-1, world).Vec3, attenuation.Vec3)
+			tempV := vec3.MultVec(rayColor(scattered, depth-1, world, rng, nil).Vec3, attenuation.Vec3)
 			return vec3.NewColor(tempV.X(), tempV.Y(), tempV.Z())
 		}
 		return vec3.NewColor(0, 0, 0)
@@ -191,5 +309,8 @@ func rayColor(r vec3.Ray, depth int, world vec3.Hittable) vec3.Color {
 	a := 0.5 * (unitDirection.Y() + 1.0)
 	ret := vec3.New(1.0, 1.0, 1.0).Mul(1.0 - a).
 		Add(vec3.New(0.5, 0.7, 1.0).Mul(a))
+	if first != nil {
+		first.albedo = vec3.NewColor(ret.X(), ret.Y(), ret.Z())
+	}
 	return vec3.NewColor(ret.X(), ret.Y(), ret.Z())
 }
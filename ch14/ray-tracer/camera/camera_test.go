@@ -0,0 +1,87 @@
+package camera
+
+import (
+	"testing"
+	"vec3/framebuffer"
+	"vec3/vec3"
+)
+
+// testScene is a small scene that draws on every kind of random sampling: diffuse,
+// fuzzy and refractive scattering, a volume, motion blur and defocus blur.
+func testScene() vec3.Hittable {
+	world := vec3.HittableList{}
+	world.Add(vec3.NewSphere(vec3.NewPoint3(0, -100.5, -1), 100, vec3.NewLambertian(vec3.NewColor(0.8, 0.8, 0.0))))
+	world.Add(vec3.NewMovingSphere(vec3.NewPoint3(0, 0, -1.2), vec3.NewPoint3(0, 0.2, -1.2), 0.5, vec3.NewLambertian(vec3.NewColor(0.1, 0.2, 0.5))))
+	world.Add(vec3.NewSphere(vec3.NewPoint3(-1, 0, -1), 0.5, vec3.NewDielectric(1.5)))
+	world.Add(vec3.NewSphere(vec3.NewPoint3(1, 0, -1), 0.5, vec3.NewMetal(vec3.NewColor(0.8, 0.6, 0.2), 0.3)))
+	fog := vec3.NewSphere(vec3.NewPoint3(0, 0.7, -2), 0.4, vec3.NewDielectric(1.5))
+	world.Add(vec3.NewConstantMedium(fog, 2, vec3.NewColor(0.9, 0.9, 0.9)))
+	return vec3.NewBVH(world)
+}
+
+func testCamera(workers int, seed uint64) Camera {
+	cam := NewCamera()
+	cam.SetAspectRatio(16.0 / 9.0)
+	cam.SetImageWidth(48)
+	cam.SetSamplesPerPixel(4)
+	cam.SetMaxDepth(8)
+	cam.SetVerticalFieldOfView(60)
+	cam.SetLookFrom(vec3.NewPoint3(0, 0.5, 1))
+	cam.SetLookAt(vec3.NewPoint3(0, 0, -1))
+	cam.SetRelativeUpDirection(vec3.New(0, 1, 0))
+	cam.SetDefocusAngle(1)
+	cam.SetFocusDistance(2)
+	cam.SetShutter(0, 1)
+	cam.SetTileSize(8)
+	cam.SetWorkers(workers)
+	cam.SetSeed(seed)
+	cam.SetAOVs(framebuffer.AOVDepth, framebuffer.AOVMaterialID, framebuffer.AOVObjectID)
+	return cam
+}
+
+// sameImage reports whether a and b hold exactly the same samples, AOVs included.
+func sameImage(t *testing.T, a *framebuffer.Framebuffer, b *framebuffer.Framebuffer) bool {
+	t.Helper()
+	if a.Width() != b.Width() || a.Height() != b.Height() {
+		t.Fatalf("sizes differ: %dx%d and %dx%d", a.Width(), a.Height(), b.Width(), b.Height())
+	}
+	for y := 0; y < a.Height(); y++ {
+		for x := 0; x < a.Width(); x++ {
+			if a.Sum(x, y) != b.Sum(x, y) || a.Samples(x, y) != b.Samples(x, y) {
+				return false
+			}
+		}
+	}
+	for _, aov := range a.AOVs() {
+		if b.AOV(aov) == nil || !sameImage(t, a.AOV(aov), b.AOV(aov)) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRenderIsIndependentOfWorkers(t *testing.T) {
+	world := testScene()
+	cam := testCamera(1, 7)
+	want := cam.Render(world)
+	for _, workers := range []int{2, 3, 8} {
+		cam := testCamera(workers, 7)
+		if got := cam.Render(world); !sameImage(t, got, want) {
+			t.Errorf("render with %d workers differs from the render with 1 worker", workers)
+		}
+	}
+}
+
+func TestRenderSeed(t *testing.T) {
+	world := testScene()
+	cam := testCamera(4, 7)
+	a := cam.Render(world)
+	cam = testCamera(4, 7)
+	if b := cam.Render(world); !sameImage(t, a, b) {
+		t.Error("two renders with the same seed differ")
+	}
+	cam = testCamera(4, 8)
+	if c := cam.Render(world); sameImage(t, a, c) {
+		t.Error("renders with different seeds are the same")
+	}
+}
@@ -0,0 +1,71 @@
+// Command denoise filters a rendered image saved as PFM, optionally guided by albedo and
+// normal AOVs of the same render, and writes the result in the format given by the
+// output file extension.
+package main
+
+import (
+	"flag"
+	"log"
+	"vec3/denoise"
+	"vec3/framebuffer"
+	"vec3/vec3"
+)
+
+func main() {
+	defaults := denoise.DefaultOptions()
+	output := flag.String("o", "denoised.png", "output image; the format follows the extension")
+	albedoPath := flag.String("albedo", "", "albedo guide image (.pfm)")
+	normalPath := flag.String("normal", "", "normal guide image (.pfm)")
+	iterations := flag.Int("iterations", defaults.Iterations, "number of filter passes")
+	colorSigma := flag.Float64("color-sigma", defaults.ColorSigma, "edge-stopping fall-off for color")
+	normalSigma := flag.Float64("normal-sigma", defaults.NormalSigma, "edge-stopping fall-off for normals")
+	albedoSigma := flag.Float64("albedo-sigma", defaults.AlbedoSigma, "edge-stopping fall-off for albedo")
+	strength := flag.Float64("strength", defaults.Strength, "blend between the input (0) and the filtered image (1)")
+	toneMapper := flag.String("tonemap", "gamma", "tone mapping operator for low dynamic range outputs")
+	exposure := flag.Float64("exposure", 0, "exposure adjustment in EV applied before tone mapping")
+	white := flag.Float64("white", 0, "radiance that maps to white with reinhard or hable; 0 keeps the default")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		log.Fatal("usage: denoise [flags] image.pfm")
+	}
+	beauty, err := framebuffer.Load(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	albedo, err := loadGuide(*albedoPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	normal, err := loadGuide(*normalPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tm, err := vec3.ParseToneMapper(*toneMapper, *exposure, *white)
+	if err != nil {
+		log.Fatal(err)
+	}
+	beauty.SetToneMapper(tm)
+
+	opts := denoise.Options{
+		Iterations:  *iterations,
+		ColorSigma:  *colorSigma,
+		NormalSigma: *normalSigma,
+		AlbedoSigma: *albedoSigma,
+		Strength:    *strength,
+	}
+	out, err := denoise.Denoise(beauty, albedo, normal, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Save(*output, nil); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func loadGuide(path string) (*framebuffer.Framebuffer, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return framebuffer.Load(path)
+}
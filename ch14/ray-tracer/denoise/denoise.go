@@ -0,0 +1,153 @@
+package denoise
+
+import (
+	"errors"
+	"math"
+	"vec3/framebuffer"
+	"vec3/vec3"
+)
+
+// Options controls the edge-avoiding à-trous wavelet filter. Each sigma sets how quickly
+// the weight of a neighbour falls off as it differs from the centre pixel in that buffer;
+// smaller values preserve more edges, and a sigma of zero or less ignores the buffer.
+type Options struct {
+	Iterations  int     // Number of filter passes; the footprint doubles with each one
+	ColorSigma  float64 // Fall-off for differences in (demodulated) color
+	NormalSigma float64 // Fall-off for differences in the normal guide
+	AlbedoSigma float64 // Fall-off for differences in the albedo guide
+	Strength    float64 // Blend between the input (0) and the fully filtered image (1)
+}
+
+func DefaultOptions() Options {
+	return Options{Iterations: 5, ColorSigma: 0.5, NormalSigma: 0.3, AlbedoSigma: 0.1, Strength: 1}
+}
+
+// B3 spline kernel used at every scale of the à-trous transform.
+var kernel = [5]float64{1.0 / 16, 1.0 / 4, 3.0 / 8, 1.0 / 4, 1.0 / 16}
+
+// Denoise filters the radiance in beauty and returns it as a new framebuffer with the
+// same sample counts and tone mapper. The albedo and normal guides, usually the AOVs of
+// the same render, keep texture and geometric edges sharp; either may be nil.
+func Denoise(beauty *framebuffer.Framebuffer, albedo *framebuffer.Framebuffer, normal *framebuffer.Framebuffer, opts Options) (*framebuffer.Framebuffer, error) {
+	w, h := beauty.Width(), beauty.Height()
+	for _, g := range []*framebuffer.Framebuffer{albedo, normal} {
+		if g != nil && (g.Width() != w || g.Height() != h) {
+			return nil, errors.New("denoise: guide buffer size differs from the image")
+		}
+	}
+
+	input := make([]vec3.Color, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			input[y*w+x] = beauty.Radiance(x, y)
+		}
+	}
+	albedoGuide := guide(albedo, w, h)
+	normalGuide := guide(normal, w, h)
+
+	// Filter the illumination rather than the final color, so texture detail carried by
+	// the albedo is not blurred away.
+	color := make([]vec3.Color, w*h)
+	for i, c := range input {
+		color[i] = c
+		if albedoGuide != nil {
+			color[i] = demodulate(c, albedoGuide[i])
+		}
+	}
+
+	next := make([]vec3.Color, w*h)
+	colorSigma := opts.ColorSigma
+	for it := 0; it < opts.Iterations; it++ {
+		step := 1 << it
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				next[y*w+x] = filterPixel(color, albedoGuide, normalGuide, w, h, x, y, step, colorSigma, opts)
+			}
+		}
+		color, next = next, color
+		// Finer scales have already removed most of the noise, so tighten the color term.
+		colorSigma /= 2
+	}
+
+	out := framebuffer.New(w, h)
+	out.SetToneMapper(beauty.ToneMapper())
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			c := color[i]
+			if albedoGuide != nil {
+				c = remodulate(c, albedoGuide[i])
+			}
+			c = vec3.Color{Vec3: input[i].Mul(1 - opts.Strength).Add(c.Mul(opts.Strength))}
+
+			n := max(beauty.Samples(x, y), 1)
+			out.Accumulate(x, y, vec3.Color{Vec3: c.Mul(float64(n))}, n)
+		}
+	}
+	return out, nil
+}
+
+func filterPixel(color []vec3.Color, albedo []vec3.Color, normal []vec3.Color, w int, h int, x int, y int, step int, colorSigma float64, opts Options) vec3.Color {
+	p := y*w + x
+	sum := vec3.New(0, 0, 0)
+	weightSum := 0.0
+	for dy := -2; dy <= 2; dy++ {
+		qy := y + dy*step
+		if qy < 0 || qy >= h {
+			continue
+		}
+		for dx := -2; dx <= 2; dx++ {
+			qx := x + dx*step
+			if qx < 0 || qx >= w {
+				continue
+			}
+			q := qy*w + qx
+
+			weight := kernel[dx+2] * kernel[dy+2]
+			weight *= edgeStop(color[p], color[q], colorSigma)
+			if normal != nil {
+				weight *= edgeStop(normal[p], normal[q], opts.NormalSigma)
+			}
+			if albedo != nil {
+				weight *= edgeStop(albedo[p], albedo[q], opts.AlbedoSigma)
+			}
+
+			sum = sum.Add(color[q].Mul(weight))
+			weightSum += weight
+		}
+	}
+	// The centre pixel always contributes, so the weight sum is never zero.
+	return vec3.Color{Vec3: sum.Div(weightSum)}
+}
+
+func edgeStop(a vec3.Color, b vec3.Color, sigma float64) float64 {
+	if sigma <= 0 {
+		return 1
+	}
+	d := a.Sub(b.Vec3).LengthSquared()
+	return math.Exp(-d / (sigma * sigma))
+}
+
+// Smallest albedo divided out of the color, to keep black surfaces from blowing up.
+const minAlbedo = 1e-3
+
+func demodulate(c vec3.Color, albedo vec3.Color) vec3.Color {
+	return vec3.NewColor(c.X()/math.Max(albedo.X(), minAlbedo), c.Y()/math.Max(albedo.Y(), minAlbedo), c.Z()/math.Max(albedo.Z(), minAlbedo))
+}
+
+func remodulate(c vec3.Color, albedo vec3.Color) vec3.Color {
+	return vec3.NewColor(c.X()*math.Max(albedo.X(), minAlbedo), c.Y()*math.Max(albedo.Y(), minAlbedo), c.Z()*math.Max(albedo.Z(), minAlbedo))
+}
+
+func guide(fb *framebuffer.Framebuffer, w int, h int) []vec3.Color {
+	if fb == nil {
+		return nil
+	}
+	g := make([]vec3.Color, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			g[y*w+x] = fb.Radiance(x, y)
+		}
+	}
+	return g
+}
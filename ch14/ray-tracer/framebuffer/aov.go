@@ -0,0 +1,75 @@
+package framebuffer
+
+import (
+	"fmt"
+	"sort"
+	"vec3/vec3"
+)
+
+// AOV names an arbitrary output variable, an extra buffer rendered alongside the beauty
+// image. Every AOV is stored in a framebuffer of its own, so any encoder can write it.
+type AOV int
+
+const (
+	AOVDepth      AOV = iota // Distance of the first hit along the camera view axis
+	AOVNormal                // World space normal at the first hit, facing the camera
+	AOVAlbedo                // Attenuation of the first scattering event
+	AOVPosition              // World space position of the first hit
+	AOVMaterialID            // 1 + index of the first hit's material in order of appearance, 0 for none
+	AOVObjectID              // 1 + index of the first hit object in the world, 0 for none
+)
+
+var aovNames = map[AOV]string{
+	AOVDepth:      "depth",
+	AOVNormal:     "normal",
+	AOVAlbedo:     "albedo",
+	AOVPosition:   "position",
+	AOVMaterialID: "material",
+	AOVObjectID:   "object",
+}
+
+func (a AOV) String() string {
+	if name, ok := aovNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("AOV(%d)", int(a))
+}
+
+func ParseAOV(name string) (AOV, error) {
+	for a, n := range aovNames {
+		if n == name {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("framebuffer: unknown AOV %q", name)
+}
+
+// EnableAOV adds an empty buffer for a, the same size as fb, and returns it. The buffer
+// holds raw values, so its tone mapper only clamps them to [0, 1].
+func (fb *Framebuffer) EnableAOV(a AOV) *Framebuffer {
+	if buf, ok := fb.aovs[a]; ok {
+		return buf
+	}
+	if fb.aovs == nil {
+		fb.aovs = map[AOV]*Framebuffer{}
+	}
+	buf := New(fb.width, fb.height)
+	buf.SetToneMapper(vec3.LinearToneMapper{})
+	fb.aovs[a] = buf
+	return buf
+}
+
+// AOV returns the buffer for a, or nil if it was not rendered.
+func (fb *Framebuffer) AOV(a AOV) *Framebuffer {
+	return fb.aovs[a]
+}
+
+// AOVs lists the rendered AOVs in a fixed order.
+func (fb *Framebuffer) AOVs() []AOV {
+	aovs := make([]AOV, 0, len(fb.aovs))
+	for a := range fb.aovs {
+		aovs = append(aovs, a)
+	}
+	sort.Slice(aovs, func(i, j int) bool { return aovs[i] < aovs[j] })
+	return aovs
+}
@@ -0,0 +1,212 @@
+package framebuffer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// EXRCompression selects how the scanline blocks of an OpenEXR file are compressed.
+type EXRCompression int
+
+const (
+	EXRNoCompression EXRCompression = iota
+	EXRZIPCompression
+)
+
+// EXREncoder writes the linear radiance as a single-part scanline OpenEXR image with R,
+// G and B channels. Channels are 16-bit half floats unless Float is set.
+type EXREncoder struct {
+	Compression EXRCompression
+	Float       bool
+}
+
+// Values from the OpenEXR file layout.
+const (
+	exrMagic        = 20000630
+	exrVersion      = 2
+	exrHalf         = 1
+	exrFloat        = 2
+	exrNone         = 0
+	exrZIP          = 3
+	exrIncreasingY  = 0
+	exrZIPScanlines = 16
+)
+
+func (e EXREncoder) Encode(w io.Writer, fb *Framebuffer) error {
+	// The data window of an OpenEXR image cannot be empty.
+	if fb.width <= 0 || fb.height <= 0 {
+		return errors.New("exr: cannot encode an empty image")
+	}
+
+	pixelType, bytesPerSample := int32(exrHalf), 2
+	if e.Float {
+		pixelType, bytesPerSample = exrFloat, 4
+	}
+	compression, linesPerBlock := byte(exrNone), 1
+	if e.Compression == EXRZIPCompression {
+		compression, linesPerBlock = exrZIP, exrZIPScanlines
+	}
+
+	header := &bytes.Buffer{}
+	le := binary.LittleEndian
+	binary.Write(header, le, uint32(exrMagic))
+	binary.Write(header, le, uint32(exrVersion))
+
+	// Channels must be listed in alphabetical order.
+	channels := &bytes.Buffer{}
+	for _, name := range []string{"B", "G", "R"} {
+		channels.WriteString(name)
+		channels.WriteByte(0)
+		binary.Write(channels, le, pixelType)
+		channels.Write([]byte{0, 0, 0, 0}) // pLinear and reserved bytes
+		binary.Write(channels, le, [2]int32{1, 1})
+	}
+	channels.WriteByte(0)
+	window := [4]int32{0, 0, int32(fb.width - 1), int32(fb.height - 1)}
+
+	writeEXRAttribute(header, "channels", "chlist", channels.Bytes())
+	writeEXRAttribute(header, "compression", "compression", []byte{compression})
+	writeEXRAttribute(header, "dataWindow", "box2i", exrBytes(window))
+	writeEXRAttribute(header, "displayWindow", "box2i", exrBytes(window))
+	writeEXRAttribute(header, "lineOrder", "lineOrder", []byte{exrIncreasingY})
+	writeEXRAttribute(header, "pixelAspectRatio", "float", exrBytes(float32(1)))
+	writeEXRAttribute(header, "screenWindowCenter", "v2f", exrBytes([2]float32{0, 0}))
+	writeEXRAttribute(header, "screenWindowWidth", "float", exrBytes(float32(1)))
+	header.WriteByte(0)
+
+	// Encode every block up front so the offset table can point at them.
+	var blocks [][]byte
+	for y := 0; y < fb.height; y += linesPerBlock {
+		lines := min(linesPerBlock, fb.height-y)
+		raw := make([]byte, 0, lines*fb.width*3*bytesPerSample)
+		for j := y; j < y+lines; j++ {
+			for c := 2; c >= 0; c-- {
+				for x := 0; x < fb.width; x++ {
+					v := float32(fb.Radiance(x, j).Axis(c))
+					if e.Float {
+						raw = le.AppendUint32(raw, math.Float32bits(v))
+					} else {
+						raw = le.AppendUint16(raw, float32ToHalf(v))
+					}
+				}
+			}
+		}
+		if compression == exrZIP {
+			raw = exrZIPCompress(raw)
+		}
+
+		block := make([]byte, 8, 8+len(raw))
+		le.PutUint32(block[0:], uint32(int32(y)))
+		le.PutUint32(block[4:], uint32(len(raw)))
+		blocks = append(blocks, append(block, raw...))
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write(header.Bytes())
+	offset := uint64(header.Len() + 8*len(blocks))
+	for _, block := range blocks {
+		binary.Write(bw, le, offset)
+		offset += uint64(len(block))
+	}
+	for _, block := range blocks {
+		bw.Write(block)
+	}
+	return bw.Flush()
+}
+
+func writeEXRAttribute(w *bytes.Buffer, name string, typ string, value []byte) {
+	w.WriteString(name)
+	w.WriteByte(0)
+	w.WriteString(typ)
+	w.WriteByte(0)
+	binary.Write(w, binary.LittleEndian, int32(len(value)))
+	w.Write(value)
+}
+
+func exrBytes(v any) []byte {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, v)
+	return buf.Bytes()
+}
+
+func exrZIPCompress(raw []byte) []byte {
+	if len(raw) == 0 {
+		return raw
+	}
+
+	// Split the bytes into even and odd positions, then delta encode them, which lets
+	// zlib find far more redundancy in floating point data.
+	tmp := make([]byte, len(raw))
+	half := (len(raw) + 1) / 2
+	for i, b := range raw {
+		if i%2 == 0 {
+			tmp[i/2] = b
+		} else {
+			tmp[half+i/2] = b
+		}
+	}
+	p := tmp[0]
+	for i := 1; i < len(tmp); i++ {
+		d := int(tmp[i]) - int(p) + 128 + 256
+		p = tmp[i]
+		tmp[i] = byte(d)
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zlib.NewWriter(buf)
+	zw.Write(tmp)
+	zw.Close()
+
+	// Blocks that do not shrink are stored uncompressed, as readers expect.
+	if buf.Len() >= len(raw) {
+		return raw
+	}
+	return buf.Bytes()
+}
+
+func float32ToHalf(f float32) uint16 {
+	// Converts to IEEE 754 binary16, rounding to nearest even and saturating to infinity.
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int32(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	if exp == 0xff {
+		if mant != 0 {
+			return sign | 0x7e00 // NaN
+		}
+		return sign | 0x7c00
+	}
+
+	e := exp - 127 + 15
+	if e >= 0x1f {
+		return sign | 0x7c00
+	}
+	if e <= 0 {
+		// Subnormal half, or zero when too small.
+		if e < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint32(14 - e)
+		h := mant >> shift
+		rem := mant & (1<<shift - 1)
+		halfway := uint32(1) << (shift - 1)
+		if rem > halfway || (rem == halfway && h&1 == 1) {
+			h++
+		}
+		return sign | uint16(h)
+	}
+
+	h := uint32(e)<<10 | mant>>13
+	rem := mant & 0x1fff
+	if rem > 0x1000 || (rem == 0x1000 && h&1 == 1) {
+		h++ // May carry into the exponent, which correctly rounds up to the next power or infinity.
+	}
+	return sign | uint16(h)
+}
@@ -0,0 +1,20 @@
+package framebuffer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEXREncoderRejectsEmptyImages(t *testing.T) {
+	for _, size := range [][2]int{{0, 4}, {4, 0}, {0, 0}} {
+		for _, enc := range []EXREncoder{{}, {Compression: EXRZIPCompression}, {Float: true}} {
+			var buf bytes.Buffer
+			if err := New(size[0], size[1]).Encode(&buf, enc); err == nil {
+				t.Errorf("%dx%d image encoded with %+v without an error", size[0], size[1], enc)
+			}
+		}
+	}
+	if got := exrZIPCompress(nil); len(got) != 0 {
+		t.Errorf("exrZIPCompress(nil) = %v", got)
+	}
+}
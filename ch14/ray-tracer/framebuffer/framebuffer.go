@@ -0,0 +1,94 @@
+package framebuffer
+
+import (
+	"image"
+	"image/color"
+	"io"
+	"vec3/vec3"
+)
+
+// Framebuffer holds the accumulated linear radiance of a render together with the
+// number of samples taken for each pixel. Nothing is tone mapped or clamped until the
+// buffer is encoded.
+type Framebuffer struct {
+	width      int
+	height     int
+	sum        []vec3.Color
+	samples    []int
+	toneMapper vec3.ToneMapper
+	aovs       map[AOV]*Framebuffer
+}
+
+// Encoder writes a framebuffer to w in some image format.
+type Encoder interface {
+	Encode(w io.Writer, fb *Framebuffer) error
+}
+
+func New(width int, height int) *Framebuffer {
+	return &Framebuffer{
+		width:   width,
+		height:  height,
+		sum:     make([]vec3.Color, width*height),
+		samples: make([]int, width*height),
+	}
+}
+
+func (fb *Framebuffer) Width() int  { return fb.width }
+func (fb *Framebuffer) Height() int { return fb.height }
+
+// ToneMapper returns the operator low dynamic range encoders use to map the radiance
+// to display values. HDR encoders write the radiance untouched.
+func (fb *Framebuffer) ToneMapper() vec3.ToneMapper {
+	if fb.toneMapper == nil {
+		return vec3.GammaToneMapper{}
+	}
+	return fb.toneMapper
+}
+
+func (fb *Framebuffer) SetToneMapper(tm vec3.ToneMapper) { fb.toneMapper = tm }
+
+// Accumulate adds the sum of samples radiance samples to the pixel at x, y. Distinct
+// pixels may be accumulated from different goroutines.
+func (fb *Framebuffer) Accumulate(x int, y int, sum vec3.Color, samples int) {
+	i := y*fb.width + x
+	fb.sum[i].Vec3 = fb.sum[i].Add(sum.Vec3)
+	fb.samples[i] += samples
+}
+
+func (fb *Framebuffer) Sum(x int, y int) vec3.Color { return fb.sum[y*fb.width+x] }
+func (fb *Framebuffer) Samples(x int, y int) int    { return fb.samples[y*fb.width+x] }
+
+// Radiance returns the mean linear radiance of the pixel at x, y.
+func (fb *Framebuffer) Radiance(x int, y int) vec3.Color {
+	i := y*fb.width + x
+	if fb.samples[i] == 0 {
+		return vec3.NewColor(0, 0, 0)
+	}
+	return vec3.Color{Vec3: fb.sum[i].Div(float64(fb.samples[i]))}
+}
+
+func (fb *Framebuffer) Encode(w io.Writer, enc Encoder) error {
+	return enc.Encode(w, fb)
+}
+
+// Image returns a tone mapped 8-bit view of the framebuffer. The view reads the
+// framebuffer on every access, so it reflects later accumulation.
+func (fb *Framebuffer) Image() image.Image {
+	return imageView{fb}
+}
+
+type imageView struct {
+	fb *Framebuffer
+}
+
+func (v imageView) ColorModel() color.Model { return color.RGBAModel }
+
+func (v imageView) Bounds() image.Rectangle { return image.Rect(0, 0, v.fb.width, v.fb.height) }
+
+func (v imageView) At(x int, y int) color.Color {
+	if !(image.Point{x, y}.In(v.Bounds())) {
+		return color.RGBA{}
+	}
+	r, g, b := v.fb.Radiance(x, y).Quantize(1, 256, v.fb.ToneMapper())
+	return color.RGBA{uint8(r), uint8(g), uint8(b), 255}
+}
@@ -0,0 +1,95 @@
+package framebuffer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+)
+
+// HDREncoder writes the linear radiance as a Radiance RGBE (.hdr) image, with the
+// scanlines run-length encoded where the format allows it.
+type HDREncoder struct{}
+
+func (HDREncoder) Encode(w io.Writer, fb *Framebuffer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y %d +X %d\n", fb.height, fb.width)
+
+	scanline := make([]byte, 4*fb.width)
+	for y := 0; y < fb.height; y++ {
+		for x := 0; x < fb.width; x++ {
+			c := fb.Radiance(x, y)
+			copy(scanline[4*x:], toRGBE(c.X(), c.Y(), c.Z()))
+		}
+		writeRGBEScanline(bw, scanline, fb.width)
+	}
+	return bw.Flush()
+}
+
+func toRGBE(r float64, g float64, b float64) []byte {
+	// Share a single exponent between the three components, from the largest of them.
+	r, g, b = math.Max(r, 0), math.Max(g, 0), math.Max(b, 0)
+	v := math.Max(r, math.Max(g, b))
+	if v < 1e-32 {
+		return []byte{0, 0, 0, 0}
+	}
+	frac, exp := math.Frexp(v)
+	scale := frac * 256 / v
+	return []byte{byte(r * scale), byte(g * scale), byte(b * scale), byte(exp + 128)}
+}
+
+func writeRGBEScanline(w *bufio.Writer, scanline []byte, width int) {
+	// Scanlines outside this range cannot be run-length encoded and are written flat.
+	if width < 8 || width > 0x7fff {
+		w.Write(scanline)
+		return
+	}
+
+	w.Write([]byte{2, 2, byte(width >> 8), byte(width & 0xff)})
+
+	// Each component is encoded separately, as runs of repeated bytes and literal dumps.
+	component := make([]byte, width)
+	for c := 0; c < 4; c++ {
+		for x := 0; x < width; x++ {
+			component[x] = scanline[4*x+c]
+		}
+		writeRLE(w, component)
+	}
+}
+
+func writeRLE(w *bufio.Writer, data []byte) {
+	const minRun = 4 // Shorter runs are cheaper to write as part of a literal dump.
+	cur := 0
+	for cur < len(data) {
+		// Find the start of the next run that is long enough to be worth encoding.
+		begRun := cur
+		runCount, oldRunCount := 0, 0
+		for runCount < minRun && begRun < len(data) {
+			begRun += runCount
+			oldRunCount = runCount
+			runCount = 1
+			for begRun+runCount < len(data) && runCount < 127 && data[begRun] == data[begRun+runCount] {
+				runCount++
+			}
+		}
+
+		// If the data before the next big run is itself a short run, write it as such.
+		if oldRunCount > 1 && oldRunCount == begRun-cur {
+			w.Write([]byte{byte(128 + oldRunCount), data[cur]})
+			cur = begRun
+		}
+
+		// Write literal bytes up to the start of the next run.
+		for cur < begRun {
+			n := min(begRun-cur, 128)
+			w.WriteByte(byte(n))
+			w.Write(data[cur : cur+n])
+			cur += n
+		}
+
+		if runCount >= minRun {
+			w.Write([]byte{byte(128 + runCount), data[begRun]})
+			cur += runCount
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package framebuffer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"vec3/vec3"
+)
+
+// PFMEncoder writes the linear radiance as a color Portable Float Map, without any
+// gamma correction or clamping.
+type PFMEncoder struct{}
+
+func (PFMEncoder) Encode(w io.Writer, fb *Framebuffer) error {
+	bw := bufio.NewWriter(w)
+
+	// A negative scale marks the data as little-endian.
+	fmt.Fprintf(bw, "PF\n%d %d\n-1.0\n", fb.width, fb.height)
+
+	// PFM stores scanlines from the bottom of the image to the top.
+	buf := make([]byte, 12)
+	for y := fb.height - 1; y >= 0; y-- {
+		for x := 0; x < fb.width; x++ {
+			c := fb.Radiance(x, y)
+			binary.LittleEndian.PutUint32(buf[0:], math.Float32bits(float32(c.X())))
+			binary.LittleEndian.PutUint32(buf[4:], math.Float32bits(float32(c.Y())))
+			binary.LittleEndian.PutUint32(buf[8:], math.Float32bits(float32(c.Z())))
+			bw.Write(buf)
+		}
+	}
+	return bw.Flush()
+}
+
+// DecodePFM reads a color (PF) or greyscale (Pf) Portable Float Map. Each pixel of the
+// returned framebuffer holds its value as a single sample.
+func DecodePFM(r io.Reader) (*Framebuffer, error) {
+	br := bufio.NewReader(r)
+	var magic string
+	var width, height int
+	var scale float64
+	if _, err := fmt.Fscan(br, &magic, &width, &height, &scale); err != nil {
+		return nil, fmt.Errorf("framebuffer: reading PFM header: %w", err)
+	}
+	// A single whitespace character separates the header from the raster.
+	if _, err := br.ReadByte(); err != nil {
+		return nil, fmt.Errorf("framebuffer: reading PFM header: %w", err)
+	}
+
+	channels := 3
+	switch magic {
+	case "PF":
+	case "Pf":
+		channels = 1
+	default:
+		return nil, fmt.Errorf("framebuffer: not a PFM file (magic %q)", magic)
+	}
+	if width <= 0 || height <= 0 || scale == 0 {
+		return nil, fmt.Errorf("framebuffer: bad PFM header %d %d %g", width, height, scale)
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if scale < 0 {
+		order = binary.LittleEndian
+	}
+
+	fb := New(width, height)
+	buf := make([]byte, 4*channels)
+	v := make([]float64, channels)
+	for y := height - 1; y >= 0; y-- {
+		for x := 0; x < width; x++ {
+			if _, err := io.ReadFull(br, buf); err != nil {
+				return nil, fmt.Errorf("framebuffer: reading PFM raster: %w", err)
+			}
+			for c := range v {
+				v[c] = float64(math.Float32frombits(order.Uint32(buf[4*c:])))
+			}
+			if channels == 1 {
+				fb.Accumulate(x, y, vec3.NewColor(v[0], v[0], v[0]), 1)
+			} else {
+				fb.Accumulate(x, y, vec3.NewColor(v[0], v[1], v[2]), 1)
+			}
+		}
+	}
+	return fb, nil
+}
@@ -0,0 +1,36 @@
+package framebuffer
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// PNGEncoder writes an 8-bit PNG, or a 16-bit one when Depth16 is set.
+type PNGEncoder struct {
+	Depth16 bool
+}
+
+func (e PNGEncoder) Encode(w io.Writer, fb *Framebuffer) error {
+	bounds := image.Rect(0, 0, fb.width, fb.height)
+	if !e.Depth16 {
+		img := image.NewRGBA(bounds)
+		for y := 0; y < fb.height; y++ {
+			for x := 0; x < fb.width; x++ {
+				r, g, b := fb.Radiance(x, y).Quantize(1, 1<<8, fb.ToneMapper())
+				img.SetRGBA(x, y, color.RGBA{uint8(r), uint8(g), uint8(b), 0xff})
+			}
+		}
+		return png.Encode(w, img)
+	}
+
+	img := image.NewRGBA64(bounds)
+	for y := 0; y < fb.height; y++ {
+		for x := 0; x < fb.width; x++ {
+			r, g, b := fb.Radiance(x, y).Quantize(1, 1<<16, fb.ToneMapper())
+			img.SetRGBA64(x, y, color.RGBA64{uint16(r), uint16(g), uint16(b), 0xffff})
+		}
+	}
+	return png.Encode(w, img)
+}
@@ -0,0 +1,37 @@
+package framebuffer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// PPMEncoder writes the ASCII (P3) variant of the PPM format.
+type PPMEncoder struct{}
+
+func (PPMEncoder) Encode(w io.Writer, fb *Framebuffer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P3\n%d %d\n255\n", fb.width, fb.height)
+	for y := 0; y < fb.height; y++ {
+		for x := 0; x < fb.width; x++ {
+			r, g, b := fb.Radiance(x, y).Quantize(1, 256, fb.ToneMapper())
+			fmt.Fprintf(bw, "%d %d %d\n", r, g, b)
+		}
+	}
+	return bw.Flush()
+}
+
+// BinaryPPMEncoder writes the binary (P6) variant of the PPM format.
+type BinaryPPMEncoder struct{}
+
+func (BinaryPPMEncoder) Encode(w io.Writer, fb *Framebuffer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P6\n%d %d\n255\n", fb.width, fb.height)
+	for y := 0; y < fb.height; y++ {
+		for x := 0; x < fb.width; x++ {
+			r, g, b := fb.Radiance(x, y).Quantize(1, 256, fb.ToneMapper())
+			bw.Write([]byte{byte(r), byte(g), byte(b)})
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,59 @@
+package framebuffer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EncoderFor picks an encoder from the extension of path.
+func EncoderFor(path string) (Encoder, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return PNGEncoder{}, nil
+	case ".ppm":
+		return BinaryPPMEncoder{}, nil
+	case ".pfm":
+		return PFMEncoder{}, nil
+	case ".hdr":
+		return HDREncoder{}, nil
+	case ".exr":
+		return EXREncoder{Compression: EXRZIPCompression}, nil
+	}
+	return nil, fmt.Errorf("framebuffer: no encoder for %q", path)
+}
+
+// Load reads a framebuffer from the file at path. Only PFM files can be read, as they
+// are the one format that holds the linear radiance losslessly.
+func Load(path string) (*Framebuffer, error) {
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".pfm" {
+		return nil, fmt.Errorf("framebuffer: cannot read %q, only .pfm files are supported", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodePFM(f)
+}
+
+// Save writes the framebuffer to the file at path with enc. A nil enc picks the encoder
+// from the extension of path.
+func (fb *Framebuffer) Save(path string, enc Encoder) error {
+	if enc == nil {
+		var err error
+		if enc, err = EncoderFor(path); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := enc.Encode(f, fb); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
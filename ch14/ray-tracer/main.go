@@ -1,33 +1,74 @@
 package main
 
 import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"vec3/camera"
+	"vec3/denoise"
+	"vec3/framebuffer"
 	"vec3/vec3"
 )
 
 func main() {
+	output := flag.String("o", "", "output image; the format follows the extension (.png, .ppm, .pfm, .hdr, .exr), ASCII PPM on stdout if empty")
+	depth16 := flag.Bool("png16", false, "write 16 bits per channel when the output is a PNG")
+	toneMapper := flag.String("tonemap", "gamma", "tone mapping operator: gamma, srgb, reinhard, aces or hable")
+	exposure := flag.Float64("exposure", 0, "exposure adjustment in EV applied before tone mapping")
+	white := flag.Float64("white", 0, "radiance that maps to white with reinhard or hable; 0 keeps the default")
+	aovList := flag.String("aov", "", "comma separated AOVs to write next to a .pfm or .exr output: depth, normal, albedo, position, material, object")
+	denoised := flag.Bool("denoise", false, "denoise the image, guided by albedo and normal AOVs")
+	flag.Parse()
+
+	tm, err := vec3.ParseToneMapper(*toneMapper, *exposure, *white)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var aovs []framebuffer.AOV
+	if *aovList != "" {
+		if *output == "" {
+			log.Fatal("AOVs need an output file to be written next to")
+		}
+		// Depths, positions, IDs and negative normal components do not survive display
+		// encodings, which clamp to [0, 1], or RGBE, which cannot store negative values.
+		if ext := strings.ToLower(filepath.Ext(*output)); ext != ".pfm" && ext != ".exr" {
+			log.Fatalf("AOVs need a floating point output, .pfm or .exr, not %q", ext)
+		}
+		for _, name := range strings.Split(*aovList, ",") {
+			a, err := framebuffer.ParseAOV(strings.TrimSpace(name))
+			if err != nil {
+				log.Fatal(err)
+			}
+			aovs = append(aovs, a)
+		}
+	}
+
+	seed := uint64(2023)
+	rng := vec3.NewPCG(seed, 0)
 	world := vec3.HittableList{}
 
 	groundMaterial := vec3.NewLambertian(vec3.NewColor(0.5, 0.5, 0.5))
-	world.Add(vec3.NewSphere(vec3.NewPoint3(0, -1000, 0), 1000, groundMaterial))
+	world.Add(vec3.NewPlane(vec3.NewPoint3(0, 0, 0), vec3.New(0, 1, 0), groundMaterial))
 
 	for a := -11; a < 11; a++ {
 		for b := -11; b < 11; b++ {
-			chooseMat := vec3.Random()
-			center := vec3.NewPoint3(float64(a)+0.9*vec3.Random(), 0.2, float64(b)+0.9*vec3.Random())
+			chooseMat := vec3.Random(rng)
+			center := vec3.NewPoint3(float64(a)+0.9*vec3.Random(rng), 0.2, float64(b)+0.9*vec3.Random(rng))
 
 			if center.Sub(vec3.New(4, 0.2, 0)).Length() > 0.9 {
 				var sphereMaterial vec3.Material
 
 				if chooseMat < 0.8 {
 					// diffuse
-					albedo := vec3.MultVec(vec3.RandomVec3(), vec3.RandomVec3())
+					albedo := vec3.MultVec(vec3.RandomVec3(rng), vec3.RandomVec3(rng))
 					sphereMaterial = vec3.NewLambertian(vec3.NewColor(albedo.X(), albedo.Y(), albedo.Z()))
 					world.Add(vec3.NewSphere(center, 0.2, sphereMaterial))
 				} else if chooseMat < 0.95 {
 					// metal
-					albedo := vec3.RandomInRangeVec3(0.5, 1)
-					fuzz := vec3.RandomInRange(0, 0.5)
+					albedo := vec3.RandomInRangeVec3(rng, 0.5, 1)
+					fuzz := vec3.RandomInRange(rng, 0, 0.5)
 					sphereMaterial = vec3.NewMetal(vec3.NewColor(albedo.X(), albedo.Y(), albedo.Z()), fuzz)
 					world.Add(vec3.NewSphere(center, 0.2, sphereMaterial))
 				} else {
@@ -63,5 +104,49 @@ func main() {
 	cam.SetDefocusAngle(0.6)
 	cam.SetFocusDistance(10.0)
 
-	cam.Render(world)
+	cam.SetSeed(seed)
+	cam.SetToneMapper(tm)
+	if *denoised {
+		cam.SetAOVs(append(aovs, framebuffer.AOVAlbedo, framebuffer.AOVNormal)...)
+	} else {
+		cam.SetAOVs(aovs...)
+	}
+
+	fb := cam.Render(vec3.NewBVH(world))
+	beauty := fb
+	if *denoised {
+		beauty, err = denoise.Denoise(fb, fb.AOV(framebuffer.AOVAlbedo), fb.AOV(framebuffer.AOVNormal), denoise.DefaultOptions())
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := write(beauty, fb, aovs, *output, *depth16); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func write(beauty *framebuffer.Framebuffer, fb *framebuffer.Framebuffer, aovs []framebuffer.AOV, output string, depth16 bool) error {
+	if output == "" {
+		return beauty.Encode(os.Stdout, framebuffer.PPMEncoder{})
+	}
+
+	enc, err := framebuffer.EncoderFor(output)
+	if err != nil {
+		return err
+	}
+	if _, ok := enc.(framebuffer.PNGEncoder); ok {
+		enc = framebuffer.PNGEncoder{Depth16: depth16}
+	}
+	if err := beauty.Save(output, enc); err != nil {
+		return err
+	}
+
+	// Each AOV goes next to the beauty image, as in image.depth.exr for image.exr.
+	ext := filepath.Ext(output)
+	for _, a := range aovs {
+		if err := fb.AOV(a).Save(strings.TrimSuffix(output, ext)+"."+a.String()+ext, enc); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,65 @@
+package meshio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"vec3/vec3"
+)
+
+// gridMagic starts a density grid file.
+const gridMagic = "DENSGRID"
+
+// LoadGrid reads the density grid file at path. See ReadGrid.
+func LoadGrid(path string) (*vec3.DensityGrid, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	grid, err := ReadGrid(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return grid, nil
+}
+
+// ReadGrid reads a density grid file: the 8 bytes "DENSGRID", the resolution along x,
+// y and z as little-endian uint32s, then the densities as little-endian float32s with
+// x varying fastest and z slowest.
+func ReadGrid(r io.Reader) (*vec3.DensityGrid, error) {
+	br := bufio.NewReader(r)
+	var header struct {
+		Magic      [8]byte
+		NX, NY, NZ uint32
+	}
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("grid: reading header: %w", err)
+	}
+	if string(header.Magic[:]) != gridMagic {
+		return nil, errors.New("grid: not a density grid file")
+	}
+	return ReadRawGrid(br, int(header.NX), int(header.NY), int(header.NZ))
+}
+
+// ReadRawGrid reads a headerless dump of nx*ny*nz little-endian float32 densities with
+// x varying fastest and z slowest, as written by many simulation tools.
+func ReadRawGrid(r io.Reader, nx int, ny int, nz int) (*vec3.DensityGrid, error) {
+	// Check each size before multiplying them, so that huge sizes cannot overflow.
+	if nx <= 0 || ny <= 0 || nz <= 0 || nx > vec3.MaxGridSamples/ny/nz {
+		return nil, fmt.Errorf("grid: invalid resolution %dx%dx%d", nx, ny, nz)
+	}
+	buf := make([]byte, 4*nx*ny*nz)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, fmt.Errorf("grid: reading %d densities: %w", nx*ny*nz, err)
+	}
+	data := make([]float64, nx*ny*nz)
+	for i := range data {
+		data[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:])))
+	}
+	return vec3.NewDensityGrid(nx, ny, nz, data)
+}
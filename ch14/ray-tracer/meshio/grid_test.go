@@ -0,0 +1,36 @@
+package meshio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestReadGridRejectsOverflowingResolution(t *testing.T) {
+	// 2^22 * 2^21 * 2^21 wraps to 0 when multiplied as 64-bit ints.
+	var buf bytes.Buffer
+	buf.WriteString(gridMagic)
+	for _, n := range []uint32{1 << 22, 1 << 21, 1 << 21} {
+		binary.Write(&buf, binary.LittleEndian, n)
+	}
+	if _, err := ReadGrid(&buf); err == nil {
+		t.Fatal("ReadGrid accepted a grid whose size overflows")
+	}
+}
+
+func TestReadGrid(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString(gridMagic)
+	binary.Write(&buf, binary.LittleEndian, []uint32{2, 1, 1})
+	binary.Write(&buf, binary.LittleEndian, []float32{0.25, 0.75})
+	grid, err := ReadGrid(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nx, ny, nz := grid.Resolution(); nx != 2 || ny != 1 || nz != 1 {
+		t.Errorf("resolution = %dx%dx%d, want 2x1x1", nx, ny, nz)
+	}
+	if grid.MaxDensity() != 0.75 {
+		t.Errorf("max density = %v, want 0.75", grid.MaxDensity())
+	}
+}
@@ -0,0 +1,131 @@
+package meshio
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"math"
+	"strconv"
+	"strings"
+	"vec3/vec3"
+)
+
+// mtlMaterial holds the MTL statements that are mapped onto materials.
+type mtlMaterial struct {
+	kd, ks vec3.Color
+	ns     float64 // Specular exponent
+	ni     float64 // Index of refraction, 0 when not given
+	d      float64 // Dissolve, 1 for opaque
+	illum  int
+}
+
+// readMTL adds the materials of the MTL file name to materials.
+func readMTL(fsys fs.FS, name string, materials map[string]vec3.Material) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var current *mtlMaterial
+	var currentName string
+	flush := func() {
+		if current != nil {
+			materials[currentName] = current.material()
+		}
+	}
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		keyword, args := fields[0], fields[1:]
+
+		lineErr := func(format string, a ...any) error {
+			return fmt.Errorf("%s:%d: %s", name, lineNo, fmt.Sprintf(format, a...))
+		}
+
+		if keyword == "newmtl" {
+			if len(args) != 1 {
+				return lineErr("newmtl needs one material name")
+			}
+			flush()
+			current = &mtlMaterial{kd: vec3.NewColor(0.8, 0.8, 0.8), d: 1, illum: 1}
+			currentName = args[0]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch keyword {
+		case "Kd", "Ks":
+			if len(args) != 3 {
+				return lineErr("%s needs an r g b color", keyword)
+			}
+			v, err := parseFloats(args, 3)
+			if err != nil {
+				return lineErr("%s: %v", keyword, err)
+			}
+			if keyword == "Kd" {
+				current.kd = vec3.NewColor(v[0], v[1], v[2])
+			} else {
+				current.ks = vec3.NewColor(v[0], v[1], v[2])
+			}
+		case "Ns", "Ni", "d", "Tr":
+			v, err := parseFloats(args, 1)
+			if err != nil || len(v) != 1 {
+				return lineErr("%s needs a single number", keyword)
+			}
+			switch keyword {
+			case "Ns":
+				current.ns = v[0]
+			case "Ni":
+				current.ni = v[0]
+			case "d":
+				current.d = v[0]
+			case "Tr":
+				current.d = 1 - v[0]
+			}
+		case "illum":
+			if len(args) != 1 {
+				return lineErr("illum needs an illumination model number")
+			}
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return lineErr("illum: %v", err)
+			}
+			current.illum = n
+		}
+		// Texture maps and the remaining statements are not supported and are skipped.
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	flush()
+	return nil
+}
+
+func (m mtlMaterial) material() vec3.Material {
+	// Transparent and refractive illumination models become glass, reflective ones and
+	// surfaces dominated by their specular color become metal, and the rest are diffuse.
+	switch {
+	case m.d < 1 || m.illum == 4 || m.illum == 6 || m.illum == 7 || m.illum == 9:
+		ir := m.ni
+		if ir <= 0 {
+			ir = 1.5
+		}
+		return vec3.NewDielectric(ir)
+	case m.illum == 3 || m.illum == 5 || (m.illum == 2 && brightness(m.ks) > brightness(m.kd)):
+		// Approximate the roughness of a Phong lobe with exponent Ns.
+		fuzz := math.Min(math.Sqrt(2/(m.ns+2)), 1)
+		return vec3.NewMetal(m.ks, fuzz)
+	}
+	return vec3.NewLambertian(m.kd)
+}
+
+func brightness(c vec3.Color) float64 {
+	return (c.X() + c.Y() + c.Z()) / 3
+}
@@ -0,0 +1,264 @@
+package meshio
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"vec3/vec3"
+)
+
+// DefaultMaterial is given to faces that have no material of their own.
+var DefaultMaterial vec3.Material = vec3.NewLambertian(vec3.NewColor(0.5, 0.5, 0.5))
+
+// LoadOBJ reads the Wavefront OBJ file at path, along with the MTL libraries it names,
+// which are looked up relative to the file's directory.
+func LoadOBJ(path string) (vec3.HittableList, error) {
+	return ReadOBJ(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+// ReadOBJ reads the OBJ file name from fsys. Polygons are triangulated as fans, and the
+// faces are split into one triangle mesh for every group and material they use.
+func ReadOBJ(fsys fs.FS, name string) (vec3.HittableList, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return vec3.HittableList{}, err
+	}
+	defer f.Close()
+
+	var positions []vec3.Point3
+	var normals []vec3.Vec3
+	var uvs []vec3.UV
+	materials := map[string]vec3.Material{}
+	builders := map[objMeshKey]*objMeshBuilder{}
+	var order []objMeshKey
+	key := objMeshKey{}
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		args := fields[1:]
+
+		lineErr := func(format string, a ...any) error {
+			return fmt.Errorf("%s:%d: %s", name, lineNo, fmt.Sprintf(format, a...))
+		}
+
+		switch fields[0] {
+		case "v":
+			v, err := parseFloats(args, 3)
+			if err != nil {
+				return vec3.HittableList{}, lineErr("vertex: %v", err)
+			}
+			positions = append(positions, vec3.NewPoint3(v[0], v[1], v[2]))
+		case "vn":
+			v, err := parseFloats(args, 3)
+			if err != nil {
+				return vec3.HittableList{}, lineErr("vertex normal: %v", err)
+			}
+			normals = append(normals, vec3.New(v[0], v[1], v[2]))
+		case "vt":
+			v, err := parseFloats(args, 1)
+			if err != nil {
+				return vec3.HittableList{}, lineErr("texture vertex: %v", err)
+			}
+			uv := vec3.UV{U: v[0]}
+			if len(v) > 1 {
+				uv.V = v[1]
+			}
+			uvs = append(uvs, uv)
+		case "f":
+			if len(args) < 3 {
+				return vec3.HittableList{}, lineErr("face has %d vertices, need at least 3", len(args))
+			}
+			corners := make([]objCorner, len(args))
+			for i, arg := range args {
+				c, err := parseCorner(arg, len(positions), len(uvs), len(normals))
+				if err != nil {
+					return vec3.HittableList{}, lineErr("face vertex %q: %v", arg, err)
+				}
+				corners[i] = c
+			}
+			b, ok := builders[key]
+			if !ok {
+				b = &objMeshBuilder{vertices: map[objCorner]int{}}
+				builders[key] = b
+				order = append(order, key)
+			}
+			for i := 1; i+1 < len(corners); i++ {
+				b.addTriangle(corners[0], corners[i], corners[i+1])
+			}
+		case "g", "o":
+			key.group = strings.Join(args, " ")
+		case "usemtl":
+			if len(args) != 1 {
+				return vec3.HittableList{}, lineErr("usemtl needs one material name")
+			}
+			if _, ok := materials[args[0]]; !ok {
+				return vec3.HittableList{}, lineErr("unknown material %q", args[0])
+			}
+			key.material = args[0]
+		case "mtllib":
+			if len(args) == 0 {
+				return vec3.HittableList{}, lineErr("mtllib needs a file name")
+			}
+			// The line names one or more libraries. When they are not all there, the rest
+			// of the line may instead be a single library whose path holds spaces.
+			libs := args
+			if !objFilesExist(fsys, name, libs) {
+				whole := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), fields[0]))
+				if objFilesExist(fsys, name, []string{whole}) {
+					libs = []string{whole}
+				}
+			}
+			for _, lib := range libs {
+				if err := readMTL(fsys, path.Join(path.Dir(name), lib), materials); err != nil {
+					return vec3.HittableList{}, lineErr("%v", err)
+				}
+			}
+		}
+		// Other statements, such as smoothing groups, lines and points, do not affect the
+		// rendered surfaces and are skipped.
+	}
+	if err := scanner.Err(); err != nil {
+		return vec3.HittableList{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	world := vec3.HittableList{}
+	for _, k := range order {
+		mat := DefaultMaterial
+		if k.material != "" {
+			mat = materials[k.material]
+		}
+		mesh, err := builders[k].build(positions, uvs, normals, mat)
+		if err != nil {
+			return vec3.HittableList{}, fmt.Errorf("%s: group %q: %w", name, k.group, err)
+		}
+		world.Add(mesh)
+	}
+	return world, nil
+}
+
+// objFilesExist reports whether every one of files, named relative to the OBJ file
+// name, exists in fsys.
+func objFilesExist(fsys fs.FS, name string, files []string) bool {
+	for _, file := range files {
+		if _, err := fs.Stat(fsys, path.Join(path.Dir(name), file)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+type objMeshKey struct {
+	group    string
+	material string
+}
+
+// objCorner is a face vertex: indices into the position, UV and normal lists, with -1
+// for attributes the corner does not have.
+type objCorner struct {
+	v, vt, vn int
+}
+
+// objMeshBuilder collects the triangles of one mesh, giving each distinct combination
+// of position, UV and normal a single shared vertex.
+type objMeshBuilder struct {
+	vertices map[objCorner]int
+	corners  []objCorner
+	indices  []int
+}
+
+func (b *objMeshBuilder) addTriangle(c0 objCorner, c1 objCorner, c2 objCorner) {
+	for _, c := range []objCorner{c0, c1, c2} {
+		i, ok := b.vertices[c]
+		if !ok {
+			i = len(b.corners)
+			b.vertices[c] = i
+			b.corners = append(b.corners, c)
+		}
+		b.indices = append(b.indices, i)
+	}
+}
+
+func (b *objMeshBuilder) build(positions []vec3.Point3, uvs []vec3.UV, normals []vec3.Vec3, mat vec3.Material) (*vec3.TriangleMesh, error) {
+	// Normals and UVs are only kept when every vertex of the mesh has them.
+	hasUVs, hasNormals := true, true
+	for _, c := range b.corners {
+		hasUVs = hasUVs && c.vt >= 0
+		hasNormals = hasNormals && c.vn >= 0
+	}
+
+	meshPositions := make([]vec3.Point3, len(b.corners))
+	var meshUVs []vec3.UV
+	var meshNormals []vec3.Vec3
+	for i, c := range b.corners {
+		meshPositions[i] = positions[c.v]
+		if hasUVs {
+			meshUVs = append(meshUVs, uvs[c.vt])
+		}
+		if hasNormals {
+			meshNormals = append(meshNormals, vec3.UnitVector(normals[c.vn]))
+		}
+	}
+	return vec3.NewTriangleMesh(meshPositions, meshNormals, meshUVs, b.indices, mat)
+}
+
+func parseCorner(s string, numPositions int, numUVs int, numNormals int) (objCorner, error) {
+	// Corners are written v, v/vt, v//vn or v/vt/vn, with 1-based indices that count back
+	// from the latest element when negative.
+	parts := strings.Split(s, "/")
+	if len(parts) > 3 {
+		return objCorner{}, fmt.Errorf("too many indices")
+	}
+	c := objCorner{-1, -1, -1}
+	refs := []*int{&c.v, &c.vt, &c.vn}
+	counts := []int{numPositions, numUVs, numNormals}
+	for i, part := range parts {
+		if part == "" {
+			if i == 0 {
+				return objCorner{}, fmt.Errorf("missing vertex index")
+			}
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return objCorner{}, err
+		}
+		if n < 0 {
+			n += counts[i]
+		} else {
+			n--
+		}
+		if n < 0 || n >= counts[i] || part == "0" {
+			return objCorner{}, fmt.Errorf("index %s out of range", part)
+		}
+		*refs[i] = n
+	}
+	return c, nil
+}
+
+func parseFloats(args []string, min int) ([]float64, error) {
+	if len(args) < min {
+		return nil, fmt.Errorf("need %d numbers, have %d", min, len(args))
+	}
+	v := make([]float64, len(args))
+	for i, arg := range args {
+		f, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%s is not finite", arg)
+		}
+		v[i] = f
+	}
+	return v, nil
+}
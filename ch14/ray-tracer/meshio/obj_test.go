@@ -0,0 +1,38 @@
+package meshio
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+)
+
+const objTriangle = `v 0 0 0
+v 1 0 0
+v 0 1 0
+usemtl %s
+f 1 2 3
+`
+
+func TestReadOBJMaterialLibraries(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.mtl":            {Data: []byte("newmtl red\nKd 1 0 0\n")},
+		"b.mtl":            {Data: []byte("newmtl blue\nKd 0 0 1\n")},
+		"my materials.mtl": {Data: []byte("newmtl green\nKd 0 1 0\n")},
+		"two.obj":          {Data: []byte("mtllib a.mtl b.mtl\n" + fmt.Sprintf(objTriangle, "blue"))},
+		"spaces.obj":       {Data: []byte("mtllib my materials.mtl\n" + fmt.Sprintf(objTriangle, "green"))},
+		"missing.obj":      {Data: []byte("mtllib a.mtl c.mtl\n" + fmt.Sprintf(objTriangle, "red"))},
+	}
+	for _, name := range []string{"two.obj", "spaces.obj"} {
+		world, err := ReadOBJ(fsys, name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(world.Hittables) != 1 {
+			t.Errorf("%s: got %d meshes, want 1", name, len(world.Hittables))
+		}
+	}
+	if _, err := ReadOBJ(fsys, "missing.obj"); err == nil {
+		t.Error("missing.obj: a missing library was not reported")
+	}
+}
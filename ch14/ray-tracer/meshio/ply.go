@@ -0,0 +1,403 @@
+package meshio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"vec3/vec3"
+)
+
+// LoadPLY reads the PLY file at path. See ReadPLY.
+func LoadPLY(path string, mat vec3.Material) (*vec3.TriangleMesh, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	mesh, err := ReadPLY(f, mat)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return mesh, nil
+}
+
+// ReadPLY reads an ASCII or binary PLY file into a triangle mesh, triangulating polygons
+// as fans. Vertex normals, UVs and colors are kept when present. Colors stored as
+// integers are taken to be sRGB encoded and are converted to linear values. When mat is
+// nil the mesh is given a VertexColorLambertian if it has colors, and DefaultMaterial
+// otherwise.
+func ReadPLY(r io.Reader, mat vec3.Material) (*vec3.TriangleMesh, error) {
+	br := bufio.NewReaderSize(r, 1<<16)
+	header, err := readPLYHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	var values plyValueReader
+	switch header.format {
+	case "ascii":
+		scanner := bufio.NewScanner(br)
+		scanner.Buffer(make([]byte, 1<<16), 1<<20)
+		scanner.Split(bufio.ScanWords)
+		values = &plyASCIIReader{scanner: scanner}
+	case "binary_little_endian":
+		values = &plyBinaryReader{r: br, order: binary.LittleEndian}
+	case "binary_big_endian":
+		values = &plyBinaryReader{r: br, order: binary.BigEndian}
+	default:
+		return nil, fmt.Errorf("ply: unsupported format %q", header.format)
+	}
+
+	var positions []vec3.Point3
+	var normals []vec3.Vec3
+	var uvs []vec3.UV
+	var colors []vec3.Color
+	var indices []int
+
+	for _, el := range header.elements {
+		switch el.name {
+		case "vertex":
+			layout := newPLYVertexLayout(el)
+			if !layout.hasPosition() {
+				return nil, errors.New("ply: vertex element has no x, y and z properties")
+			}
+			row := make([]float64, len(el.props))
+			for i := 0; i < el.count; i++ {
+				if err := readPLYRow(values, el, row, nil); err != nil {
+					return nil, fmt.Errorf("ply: vertex %d: %w", i, err)
+				}
+				positions = append(positions, vec3.NewPoint3(row[layout.x], row[layout.y], row[layout.z]))
+				if layout.hasNormal() {
+					normals = append(normals, vec3.New(row[layout.nx], row[layout.ny], row[layout.nz]))
+				}
+				if layout.hasUV() {
+					uvs = append(uvs, vec3.UV{U: row[layout.u], V: row[layout.v]})
+				}
+				if layout.hasColor() {
+					colors = append(colors, vec3.NewColor(
+						layout.colorValue(el, row, layout.red),
+						layout.colorValue(el, row, layout.green),
+						layout.colorValue(el, row, layout.blue)))
+				}
+			}
+		case "face":
+			list := -1
+			for p, prop := range el.props {
+				if prop.isList && (prop.name == "vertex_indices" || prop.name == "vertex_index") {
+					list = p
+				}
+			}
+			if list < 0 {
+				return nil, errors.New("ply: face element has no vertex_indices property")
+			}
+			row := make([]float64, len(el.props))
+			var face []float64
+			for i := 0; i < el.count; i++ {
+				if err := readPLYRow(values, el, row, func(p int, items []float64) {
+					if p == list {
+						face = append(face[:0], items...)
+					}
+				}); err != nil {
+					return nil, fmt.Errorf("ply: face %d: %w", i, err)
+				}
+				if len(face) < 3 {
+					return nil, fmt.Errorf("ply: face %d has %d vertices, need at least 3", i, len(face))
+				}
+				for k := 1; k+1 < len(face); k++ {
+					indices = append(indices, int(face[0]), int(face[k]), int(face[k+1]))
+				}
+			}
+		default:
+			// Skip elements such as edges and materials.
+			row := make([]float64, len(el.props))
+			for i := 0; i < el.count; i++ {
+				if err := readPLYRow(values, el, row, nil); err != nil {
+					return nil, fmt.Errorf("ply: %s %d: %w", el.name, i, err)
+				}
+			}
+		}
+	}
+
+	for i := range normals {
+		if !normals[i].NearZero() {
+			normals[i] = vec3.UnitVector(normals[i])
+		}
+	}
+	if mat == nil {
+		mat = DefaultMaterial
+		if colors != nil {
+			mat = vec3.NewVertexColorLambertian(vec3.NewColor(0.5, 0.5, 0.5))
+		}
+	}
+	mesh, err := vec3.NewTriangleMesh(positions, normals, uvs, indices, mat)
+	if err != nil {
+		return nil, fmt.Errorf("ply: %w", err)
+	}
+	if colors != nil {
+		if err := mesh.SetVertexColors(colors); err != nil {
+			return nil, fmt.Errorf("ply: %w", err)
+		}
+	}
+	return mesh, nil
+}
+
+type plyType struct {
+	size    int
+	integer bool
+	signed  bool
+	max     float64 // Largest value of an integer type, used to normalize colors
+}
+
+var plyTypes = map[string]plyType{
+	"char":    {1, true, true, math.MaxInt8},
+	"int8":    {1, true, true, math.MaxInt8},
+	"uchar":   {1, true, false, math.MaxUint8},
+	"uint8":   {1, true, false, math.MaxUint8},
+	"short":   {2, true, true, math.MaxInt16},
+	"int16":   {2, true, true, math.MaxInt16},
+	"ushort":  {2, true, false, math.MaxUint16},
+	"uint16":  {2, true, false, math.MaxUint16},
+	"int":     {4, true, true, math.MaxInt32},
+	"int32":   {4, true, true, math.MaxInt32},
+	"uint":    {4, true, false, math.MaxUint32},
+	"uint32":  {4, true, false, math.MaxUint32},
+	"float":   {4, false, true, 1},
+	"float32": {4, false, true, 1},
+	"double":  {8, false, true, 1},
+	"float64": {8, false, true, 1},
+}
+
+type plyProperty struct {
+	name      string
+	typ       plyType
+	isList    bool
+	countType plyType
+}
+
+type plyElement struct {
+	name  string
+	count int
+	props []plyProperty
+}
+
+type plyHeader struct {
+	format   string
+	elements []plyElement
+}
+
+func readPLYHeader(br *bufio.Reader) (plyHeader, error) {
+	var h plyHeader
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return h, fmt.Errorf("ply: header line %d: %w", lineNo, err)
+		}
+		fields := strings.Fields(line)
+		lineErr := func(format string, a ...any) error {
+			return fmt.Errorf("ply: header line %d: %s", lineNo, fmt.Sprintf(format, a...))
+		}
+
+		if lineNo == 1 {
+			if len(fields) != 1 || fields[0] != "ply" {
+				return h, errors.New("ply: missing ply magic number")
+			}
+			continue
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "format":
+			if len(fields) != 3 {
+				return h, lineErr("format needs a type and a version")
+			}
+			h.format = fields[1]
+		case "element":
+			if len(fields) != 3 {
+				return h, lineErr("element needs a name and a count")
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil || n < 0 {
+				return h, lineErr("bad element count %q", fields[2])
+			}
+			h.elements = append(h.elements, plyElement{name: fields[1], count: n})
+		case "property":
+			if len(h.elements) == 0 {
+				return h, lineErr("property before any element")
+			}
+			var prop plyProperty
+			var ok bool
+			if len(fields) == 5 && fields[1] == "list" {
+				prop.isList = true
+				prop.name = fields[4]
+				if prop.countType, ok = plyTypes[fields[2]]; !ok || !prop.countType.integer {
+					return h, lineErr("bad list count type %q", fields[2])
+				}
+				if prop.typ, ok = plyTypes[fields[3]]; !ok {
+					return h, lineErr("unknown type %q", fields[3])
+				}
+			} else if len(fields) == 3 {
+				prop.name = fields[2]
+				if prop.typ, ok = plyTypes[fields[1]]; !ok {
+					return h, lineErr("unknown type %q", fields[1])
+				}
+			} else {
+				return h, lineErr("malformed property")
+			}
+			el := &h.elements[len(h.elements)-1]
+			el.props = append(el.props, prop)
+		case "end_header":
+			if h.format == "" {
+				return h, lineErr("no format given")
+			}
+			return h, nil
+		case "comment", "obj_info":
+		default:
+			return h, lineErr("unknown keyword %q", fields[0])
+		}
+	}
+}
+
+// plyVertexLayout gives the index of every known property of a vertex, or -1.
+type plyVertexLayout struct {
+	x, y, z, nx, ny, nz, u, v, red, green, blue int
+}
+
+func newPLYVertexLayout(el plyElement) plyVertexLayout {
+	l := plyVertexLayout{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}
+	for p, prop := range el.props {
+		if prop.isList {
+			continue
+		}
+		switch prop.name {
+		case "x":
+			l.x = p
+		case "y":
+			l.y = p
+		case "z":
+			l.z = p
+		case "nx":
+			l.nx = p
+		case "ny":
+			l.ny = p
+		case "nz":
+			l.nz = p
+		case "u", "s", "texture_u", "texture_s":
+			l.u = p
+		case "v", "t", "texture_v", "texture_t":
+			l.v = p
+		case "red", "r", "diffuse_red":
+			l.red = p
+		case "green", "g", "diffuse_green":
+			l.green = p
+		case "blue", "b", "diffuse_blue":
+			l.blue = p
+		}
+	}
+	return l
+}
+
+func (l plyVertexLayout) hasPosition() bool { return l.x >= 0 && l.y >= 0 && l.z >= 0 }
+func (l plyVertexLayout) hasNormal() bool   { return l.nx >= 0 && l.ny >= 0 && l.nz >= 0 }
+func (l plyVertexLayout) hasUV() bool       { return l.u >= 0 && l.v >= 0 }
+func (l plyVertexLayout) hasColor() bool    { return l.red >= 0 && l.green >= 0 && l.blue >= 0 }
+
+func (l plyVertexLayout) colorValue(el plyElement, row []float64, p int) float64 {
+	typ := el.props[p].typ
+	if !typ.integer {
+		return row[p]
+	}
+	return vec3.SRGBToLinear(row[p] / typ.max)
+}
+
+// plyValueReader reads the next value of the body, whatever the encoding.
+type plyValueReader interface {
+	next(t plyType) (float64, error)
+}
+
+func readPLYRow(values plyValueReader, el plyElement, row []float64, list func(p int, items []float64)) error {
+	var items []float64
+	for p, prop := range el.props {
+		if !prop.isList {
+			v, err := values.next(prop.typ)
+			if err != nil {
+				return err
+			}
+			row[p] = v
+			continue
+		}
+		n, err := values.next(prop.countType)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return fmt.Errorf("negative list length %g", n)
+		}
+		items = items[:0]
+		for k := 0; k < int(n); k++ {
+			v, err := values.next(prop.typ)
+			if err != nil {
+				return err
+			}
+			items = append(items, v)
+		}
+		if list != nil {
+			list(p, items)
+		}
+	}
+	return nil
+}
+
+type plyASCIIReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *plyASCIIReader) next(t plyType) (float64, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return 0, err
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+	return strconv.ParseFloat(r.scanner.Text(), 64)
+}
+
+type plyBinaryReader struct {
+	r     *bufio.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (r *plyBinaryReader) next(t plyType) (float64, error) {
+	b := r.buf[:t.size]
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	switch {
+	case t.size == 1 && t.signed:
+		return float64(int8(b[0])), nil
+	case t.size == 1:
+		return float64(b[0]), nil
+	case t.size == 2 && t.signed:
+		return float64(int16(r.order.Uint16(b))), nil
+	case t.size == 2:
+		return float64(r.order.Uint16(b)), nil
+	case t.size == 4 && !t.integer:
+		return float64(math.Float32frombits(r.order.Uint32(b))), nil
+	case t.size == 4 && t.signed:
+		return float64(int32(r.order.Uint32(b))), nil
+	case t.size == 4:
+		return float64(r.order.Uint32(b)), nil
+	}
+	return math.Float64frombits(r.order.Uint64(b)), nil
+}
@@ -0,0 +1,257 @@
+package meshio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"vec3/vec3"
+)
+
+// STLOptions controls how STL facets are turned into a mesh.
+type STLOptions struct {
+	// Smooth generates vertex normals by averaging the normals of adjacent facets. Facets
+	// meeting at more than CreaseAngle degrees keep a hard edge between them.
+	Smooth      bool
+	CreaseAngle float64
+}
+
+// LoadSTL reads the STL file at path. See ReadSTL.
+func LoadSTL(path string, mat vec3.Material, opts STLOptions) (*vec3.TriangleMesh, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	mesh, err := ReadSTL(f, mat, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return mesh, nil
+}
+
+// ReadSTL reads an ASCII or binary STL file into a triangle mesh. STL stores every facet
+// with its own copy of its corners, so coincident corners are welded into shared
+// vertices. A nil mat gives the mesh DefaultMaterial.
+func ReadSTL(r io.Reader, mat vec3.Material, opts STLOptions) (*vec3.TriangleMesh, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var facets [][3]vec3.Point3
+	if isBinarySTL(data) {
+		facets, err = readBinarySTL(data)
+	} else {
+		facets, err = readASCIISTL(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if mat == nil {
+		mat = DefaultMaterial
+	}
+	positions, indices := weldSTL(facets)
+	if !opts.Smooth {
+		mesh, err := vec3.NewTriangleMesh(positions, nil, nil, indices, mat)
+		if err != nil {
+			return nil, fmt.Errorf("stl: %w", err)
+		}
+		return mesh, nil
+	}
+
+	positions, normals, indices := smoothNormals(positions, indices, opts.CreaseAngle)
+	mesh, err := vec3.NewTriangleMesh(positions, normals, nil, indices, mat)
+	if err != nil {
+		return nil, fmt.Errorf("stl: %w", err)
+	}
+	return mesh, nil
+}
+
+func isBinarySTL(data []byte) bool {
+	// Binary files may also start with "solid", so trust the size implied by the facet
+	// count in the binary header when it matches exactly.
+	if len(data) < 84 {
+		return false
+	}
+	n := binary.LittleEndian.Uint32(data[80:84])
+	if uint64(len(data)) == 84+50*uint64(n) {
+		return true
+	}
+	return !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("solid"))
+}
+
+func readBinarySTL(data []byte) ([][3]vec3.Point3, error) {
+	if len(data) < 84 {
+		return nil, errors.New("stl: binary header is truncated")
+	}
+	n := int(binary.LittleEndian.Uint32(data[80:84]))
+	if len(data) < 84+50*n {
+		return nil, fmt.Errorf("stl: file holds %d bytes, too few for %d facets", len(data), n)
+	}
+
+	// Each facet is a normal, three vertices and a 16-bit attribute; the stored normal is
+	// ignored in favour of the winding order, which writers get right more often.
+	facets := make([][3]vec3.Point3, n)
+	for i := range facets {
+		rec := data[84+50*i:]
+		for k := 0; k < 3; k++ {
+			off := 12 + 12*k
+			facets[i][k] = vec3.NewPoint3(
+				float64(math.Float32frombits(binary.LittleEndian.Uint32(rec[off:]))),
+				float64(math.Float32frombits(binary.LittleEndian.Uint32(rec[off+4:]))),
+				float64(math.Float32frombits(binary.LittleEndian.Uint32(rec[off+8:]))))
+		}
+	}
+	return facets, nil
+}
+
+func readASCIISTL(data []byte) ([][3]vec3.Point3, error) {
+	var facets [][3]vec3.Point3
+	var corners []vec3.Point3
+	inLoop := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		lineErr := func(format string, a ...any) error {
+			return fmt.Errorf("stl: line %d: %s", lineNo, fmt.Sprintf(format, a...))
+		}
+
+		switch fields[0] {
+		case "solid", "endsolid", "facet", "endfacet":
+		case "outer":
+			if inLoop {
+				return nil, lineErr("nested loop")
+			}
+			inLoop = true
+			corners = corners[:0]
+		case "vertex":
+			if !inLoop {
+				return nil, lineErr("vertex outside of a loop")
+			}
+			v, err := parseFloats(fields[1:], 3)
+			if err != nil || len(v) != 3 {
+				return nil, lineErr("vertex needs three coordinates")
+			}
+			corners = append(corners, vec3.NewPoint3(v[0], v[1], v[2]))
+		case "endloop":
+			if !inLoop || len(corners) < 3 {
+				return nil, lineErr("loop needs at least three vertices")
+			}
+			for k := 1; k+1 < len(corners); k++ {
+				facets = append(facets, [3]vec3.Point3{corners[0], corners[k], corners[k+1]})
+			}
+			inLoop = false
+		default:
+			return nil, lineErr("unknown keyword %q", fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("stl: %w", err)
+	}
+	if inLoop {
+		return nil, errors.New("stl: unterminated loop")
+	}
+	return facets, nil
+}
+
+func weldSTL(facets [][3]vec3.Point3) ([]vec3.Point3, []int) {
+	// Corners are welded when they are bit-for-bit equal, which is how CAD exporters
+	// write shared vertices.
+	ids := map[vec3.Point3]int{}
+	var positions []vec3.Point3
+	indices := make([]int, 0, 3*len(facets))
+	for _, f := range facets {
+		for _, p := range f {
+			if p.X() == 0 && p.Y() == 0 && p.Z() == 0 {
+				p = vec3.NewPoint3(0, 0, 0) // Fold negative zeros onto zero.
+			}
+			id, ok := ids[p]
+			if !ok {
+				id = len(positions)
+				ids[p] = id
+				positions = append(positions, p)
+			}
+			indices = append(indices, id)
+		}
+	}
+	return positions, indices
+}
+
+func smoothNormals(positions []vec3.Point3, indices []int, creaseAngle float64) ([]vec3.Point3, []vec3.Vec3, []int) {
+	// Every corner gets the area weighted average normal of the facets around its vertex
+	// that lie within the crease angle of its own facet. Corners of the same vertex that
+	// end up with the same normal share a new vertex; the others split it.
+	numFaces := len(indices) / 3
+	faceNormals := make([]vec3.Vec3, numFaces)
+	for f := range faceNormals {
+		a, b, c := positions[indices[3*f]], positions[indices[3*f+1]], positions[indices[3*f+2]]
+		e1, e2 := b.Sub(a.Vec3), c.Sub(a.Vec3)
+		n := vec3.Cross(e1, e2) // Length is twice the area
+
+		// Facets whose edges are parallel up to rounding have no normal. The test is
+		// relative to the edges, so that it holds at any scale.
+		if n.LengthSquared() <= 1e-24*e1.LengthSquared()*e2.LengthSquared() {
+			n = vec3.New(0, 0, 0)
+		}
+		faceNormals[f] = n
+	}
+
+	facesOf := make([][]int, len(positions))
+	for i, v := range indices {
+		facesOf[v] = append(facesOf[v], i/3)
+	}
+
+	cosCrease := math.Cos(vec3.DegreesToRadians(creaseAngle))
+	type vertexKey struct {
+		position int
+		normal   vec3.Vec3
+	}
+	ids := map[vertexKey]int{}
+	var outPositions []vec3.Point3
+	var outNormals []vec3.Vec3
+	outIndices := make([]int, len(indices))
+
+	for i, v := range indices {
+		own := faceNormals[i/3]
+		if own.LengthSquared() > 0 {
+			own = vec3.UnitVector(own)
+		}
+
+		sum := vec3.New(0, 0, 0)
+		for _, f := range facesOf[v] {
+			n := faceNormals[f]
+			if n.LengthSquared() == 0 {
+				continue
+			}
+			if f == i/3 || vec3.Dot(vec3.UnitVector(n), own) >= cosCrease {
+				sum = sum.Add(n)
+			}
+		}
+		normal := own
+		if sum.LengthSquared() > 0 {
+			normal = vec3.UnitVector(sum)
+		}
+
+		key := vertexKey{v, normal}
+		id, ok := ids[key]
+		if !ok {
+			id = len(outPositions)
+			ids[key] = id
+			outPositions = append(outPositions, positions[v])
+			outNormals = append(outNormals, normal)
+		}
+		outIndices[i] = id
+	}
+	return outPositions, outNormals, outIndices
+}
@@ -0,0 +1,27 @@
+package meshio
+
+import (
+	"testing"
+	"vec3/vec3"
+)
+
+func TestSmoothNormalsAtSmallScale(t *testing.T) {
+	// A square of two facets, at the scale of a part modelled in metres to a tenth of
+	// a millimetre, together with a degenerate facet along one of its edges.
+	for _, s := range []float64{1, 1e-4, 1e-8} {
+		positions := []vec3.Point3{
+			vec3.NewPoint3(0, 0, 0),
+			vec3.NewPoint3(s, 0, 0),
+			vec3.NewPoint3(s, s, 0),
+			vec3.NewPoint3(0, s, 0),
+			vec3.NewPoint3(s/2, 0, 0),
+		}
+		indices := []int{0, 1, 2, 0, 2, 3, 0, 4, 1}
+		_, normals, outIndices := smoothNormals(positions, indices, 30)
+		for i, id := range outIndices[:6] {
+			if n := normals[id]; n.Sub(vec3.New(0, 0, 1)).Length() > 1e-9 {
+				t.Errorf("scale %v: corner %d has normal %v, want (0, 0, 1)", s, i, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package sdf
+
+import (
+	"math"
+	"vec3/vec3"
+)
+
+// Primitives, centered on the origin.
+
+func Sphere(radius float64) Func {
+	return func(p vec3.Vec3) float64 {
+		return p.Length() - radius
+	}
+}
+
+// Box is the box from -halfSize to halfSize.
+func Box(halfSize vec3.Vec3) Func {
+	return RoundBox(halfSize, 0)
+}
+
+// RoundBox is the box from -halfSize to halfSize with its edges rounded off to radius.
+func RoundBox(halfSize vec3.Vec3, radius float64) Func {
+	return func(p vec3.Vec3) float64 {
+		qx := math.Abs(p.X()) - halfSize.X() + radius
+		qy := math.Abs(p.Y()) - halfSize.Y() + radius
+		qz := math.Abs(p.Z()) - halfSize.Z() + radius
+		outside := vec3.New(math.Max(qx, 0), math.Max(qy, 0), math.Max(qz, 0)).Length()
+		inside := math.Min(math.Max(qx, math.Max(qy, qz)), 0)
+		return outside + inside - radius
+	}
+}
+
+// Torus is a ring around the y axis with a tube of minorRadius at majorRadius from it.
+func Torus(majorRadius float64, minorRadius float64) Func {
+	return func(p vec3.Vec3) float64 {
+		q := math.Hypot(p.X(), p.Z()) - majorRadius
+		return math.Hypot(q, p.Y()) - minorRadius
+	}
+}
+
+// Mandelbulb is the Mandelbulb fractal of the given power, 8 for the classic shape,
+// which lies within a radius of about 1.2. It gives a distance estimate rather than an
+// exact distance.
+func Mandelbulb(power float64, iterations int) Func {
+	return func(p vec3.Vec3) float64 {
+		z := p
+		dr := 1.0
+		r := 0.0
+		for i := 0; i < iterations; i++ {
+			r = z.Length()
+			if r > 2 {
+				break
+			}
+
+			// Raise z to the power in spherical coordinates, tracking the derivative.
+			theta := math.Acos(z.Z()/r) * power
+			phi := math.Atan2(z.Y(), z.X()) * power
+			dr = math.Pow(r, power-1)*power*dr + 1
+			zr := math.Pow(r, power)
+			z = vec3.New(math.Sin(theta)*math.Cos(phi), math.Sin(phi)*math.Sin(theta), math.Cos(theta)).Mul(zr).Add(p)
+		}
+		if r == 0 {
+			return 0
+		}
+		return 0.5 * math.Log(r) * r / dr
+	}
+}
+
+// Combinators.
+
+func Union(a Func, b Func) Func {
+	return func(p vec3.Vec3) float64 { return math.Min(a(p), b(p)) }
+}
+
+func Intersection(a Func, b Func) Func {
+	return func(p vec3.Vec3) float64 { return math.Max(a(p), b(p)) }
+}
+
+// Difference is a with b cut away.
+func Difference(a Func, b Func) Func {
+	return func(p vec3.Vec3) float64 { return math.Max(a(p), -b(p)) }
+}
+
+// SmoothMin blends a and b with the polynomial smooth minimum, rounding their seam
+// over a distance of about k.
+func SmoothMin(a float64, b float64, k float64) float64 {
+	if k <= 0 {
+		return math.Min(a, b)
+	}
+	h := math.Max(0, math.Min(1, 0.5+0.5*(b-a)/k))
+	return b + (a-b)*h - k*h*(1-h)
+}
+
+// SmoothUnion is the union of a and b with the seam filleted over about k.
+func SmoothUnion(a Func, b Func, k float64) Func {
+	return func(p vec3.Vec3) float64 { return SmoothMin(a(p), b(p), k) }
+}
+
+// Translate moves f by offset.
+func Translate(f Func, offset vec3.Vec3) Func {
+	return func(p vec3.Vec3) float64 { return f(p.Sub(offset)) }
+}
+
+// Scale scales f uniformly by factor, which must be positive.
+func Scale(f Func, factor float64) Func {
+	return func(p vec3.Vec3) float64 { return f(p.Div(factor)) * factor }
+}
+
+// Twist rotates f around the y axis by rate radians per unit of height. Twisting
+// stretches distances, so trace the result with a step scale below 1.
+func Twist(f Func, rate float64) Func {
+	return func(p vec3.Vec3) float64 {
+		c, s := math.Cos(rate*p.Y()), math.Sin(rate*p.Y())
+		return f(vec3.New(c*p.X()-s*p.Z(), p.Y(), s*p.X()+c*p.Z()))
+	}
+}
+
+// Repeat tiles space with copies of f, spaced by period along each axis. A period of
+// zero or less leaves that axis alone. Each copy should fit within its own cell.
+func Repeat(f Func, period vec3.Vec3) Func {
+	wrap := func(x float64, period float64) float64 {
+		if period <= 0 {
+			return x
+		}
+		return x - period*math.Round(x/period)
+	}
+	return func(p vec3.Vec3) float64 {
+		return f(vec3.New(wrap(p.X(), period.X()), wrap(p.Y(), period.Y()), wrap(p.Z(), period.Z())))
+	}
+}
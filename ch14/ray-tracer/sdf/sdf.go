@@ -0,0 +1,115 @@
+// Package sdf renders shapes given by signed distance functions, which are negative
+// inside the shape, positive outside and zero on its surface, by sphere tracing.
+package sdf
+
+import (
+	"math"
+	"vec3/vec3"
+)
+
+// Func is a signed distance function. It must never overestimate the distance to the
+// surface, or the tracer can step through it.
+type Func func(p vec3.Vec3) float64
+
+// Shape is the surface where a distance function is zero, traced within a bounding box.
+type Shape struct {
+	f         Func
+	bbox      vec3.AABB
+	mat       vec3.Material
+	stepScale float64
+	maxSteps  int
+}
+
+// New returns the shape of f within bounds, which must enclose its whole surface.
+func New(f Func, bounds vec3.AABB, material vec3.Material) Shape {
+	return Shape{f: f, bbox: bounds, mat: material, stepScale: 1, maxSteps: 512}
+}
+
+// SetStepScale scales each step of the tracer. Values below 1 keep functions that
+// overestimate distances, such as twisted shapes, from stepping through the surface.
+func (s *Shape) SetStepScale(scale float64) {
+	s.stepScale = scale
+}
+
+func (s *Shape) SetMaxSteps(steps int) {
+	s.maxSteps = steps
+}
+
+// surfaceEpsilon is the distance, relative to the distance traveled, at which the
+// tracer takes a point to be on the surface.
+const surfaceEpsilon = 1e-5
+
+func (s Shape) Hit(r vec3.Ray, rayT vec3.Interval, rng vec3.RNG) (bool, vec3.Hit) {
+	inside, ok := s.bbox.Clip(r, rayT)
+	if !ok {
+		return false, vec3.Hit{}
+	}
+
+	// March with a unit direction, so that distances and ray parameters agree.
+	length := r.Direction().Length()
+	dir := r.Direction().Div(length)
+	origin := r.Origin().Vec3
+	t := inside.Min() * length
+	tMax := inside.Max() * length
+
+	// A ray that starts inside the shape marches towards where it leaves, so the sign
+	// of the distance at the start decides which way is towards the surface. A ray that
+	// starts on the surface, like one scattered from it, first has to get clear of it,
+	// and whether it heads in or out follows from the normal there.
+	start := origin.Add(dir.Mul(t))
+	startDistance := s.f(start)
+	sign := 1.0
+	if startDistance < 0 {
+		sign = -1
+	}
+	escaping := math.Abs(startDistance) < surfaceEpsilon*math.Max(1, t)
+	if escaping {
+		sign = 1
+		if vec3.Dot(s.normal(start), dir) < 0 {
+			sign = -1
+		}
+	}
+
+	for i := 0; i < s.maxSteps && t <= tMax; i++ {
+		p := origin.Add(dir.Mul(t))
+		d := sign * s.f(p)
+		threshold := surfaceEpsilon * math.Max(1, t)
+		if d < threshold && escaping {
+			t += threshold
+			continue
+		}
+		escaping = false
+		if d < threshold {
+			hitT := t / length
+			if !rayT.Surrounds(hitT) {
+				return false, vec3.Hit{}
+			}
+			outwardNormal := s.normal(p)
+			hitRecord := vec3.NewHit(vec3.NewPoint3(p.X(), p.Y(), p.Z()), outwardNormal, hitT)
+			hitRecord.SetFaceNormal(r, outwardNormal)
+			hitRecord.SetMaterial(s.mat)
+			return true, hitRecord
+		}
+		t += d * s.stepScale
+	}
+	return false, vec3.Hit{}
+}
+
+// normal estimates the outward normal at p from the gradient of the distance function,
+// by central differences.
+func (s Shape) normal(p vec3.Vec3) vec3.Vec3 {
+	const h = 1e-5
+	dx := vec3.New(h, 0, 0)
+	dy := vec3.New(0, h, 0)
+	dz := vec3.New(0, 0, h)
+	gradient := vec3.New(
+		s.f(p.Add(dx))-s.f(p.Sub(dx)),
+		s.f(p.Add(dy))-s.f(p.Sub(dy)),
+		s.f(p.Add(dz))-s.f(p.Sub(dz)))
+	if gradient.NearZero() {
+		return vec3.New(0, 1, 0)
+	}
+	return vec3.UnitVector(gradient)
+}
+
+func (s Shape) BoundingBox() vec3.AABB { return s.bbox }
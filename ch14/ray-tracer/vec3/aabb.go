@@ -0,0 +1,147 @@
+package vec3
+
+import "math"
+
+// slabErrorBound bounds the relative rounding error of the slab test, 3 ulps (see
+// Pharr, Jakob and Humphreys, Physically Based Rendering, section 3.9).
+const slabErrorBound = 3 * 0x1p-53 / (1 - 3*0x1p-53)
+
+// AABB is an axis-aligned bounding box, stored as one interval per axis.
+type AABB struct {
+	x, y, z Interval
+}
+
+func NewAABB(x Interval, y Interval, z Interval) AABB {
+	b := AABB{x, y, z}
+	b.padToMinimums()
+	return b
+}
+
+func NewAABBFromPoints(a Point3, b Point3) AABB {
+	// Treat the two points a and b as extrema for the bounding box, so we don't require a
+	// particular minimum/maximum coordinate order.
+	return NewAABB(
+		NewInterval(math.Min(a.x, b.x), math.Max(a.x, b.x)),
+		NewInterval(math.Min(a.y, b.y), math.Max(a.y, b.y)),
+		NewInterval(math.Min(a.z, b.z), math.Max(a.z, b.z)))
+}
+
+func NewAABBEnclosing(a AABB, b AABB) AABB {
+	return AABB{
+		NewIntervalEnclosing(a.x, b.x),
+		NewIntervalEnclosing(a.y, b.y),
+		NewIntervalEnclosing(a.z, b.z)}
+}
+
+func EmptyAABB() AABB {
+	return AABB{Empty, Empty, Empty}
+}
+
+// UniverseAABB is the bounding box of shapes, like planes, that extend without limit.
+func UniverseAABB() AABB {
+	return AABB{Universe, Universe, Universe}
+}
+
+func (b AABB) IsBounded() bool {
+	for axis := 0; axis < 3; axis++ {
+		ax := b.Axis(axis)
+		if math.IsInf(ax.min, 0) || math.IsInf(ax.max, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b AABB) X() Interval { return b.x }
+func (b AABB) Y() Interval { return b.y }
+func (b AABB) Z() Interval { return b.z }
+
+func (b AABB) Axis(n int) Interval {
+	if n == 1 {
+		return b.y
+	}
+	if n == 2 {
+		return b.z
+	}
+	return b.x
+}
+
+func (b AABB) LongestAxis() int {
+	// Returns the index of the longest axis of the bounding box.
+	if b.x.Size() > b.y.Size() {
+		if b.x.Size() > b.z.Size() {
+			return 0
+		}
+		return 2
+	}
+	if b.y.Size() > b.z.Size() {
+		return 1
+	}
+	return 2
+}
+
+func (b AABB) Centroid() Point3 {
+	return NewPoint3(
+		0.5*(b.x.min+b.x.max),
+		0.5*(b.y.min+b.y.max),
+		0.5*(b.z.min+b.z.max))
+}
+
+func (b AABB) SurfaceArea() float64 {
+	dx, dy, dz := b.x.Size(), b.y.Size(), b.z.Size()
+	if dx < 0 || dy < 0 || dz < 0 {
+		return 0
+	}
+	return 2 * (dx*dy + dy*dz + dz*dx)
+}
+
+func (b AABB) Hit(r Ray, rayT Interval) bool {
+	_, hit := b.Clip(r, rayT)
+	return hit
+}
+
+// Clip returns the part of rayT where r is inside the box, and false if there is none.
+func (b AABB) Clip(r Ray, rayT Interval) (Interval, bool) {
+	origin := [3]float64{r.origin.x, r.origin.y, r.origin.z}
+	direction := [3]float64{r.direction.x, r.direction.y, r.direction.z}
+
+	for axis := 0; axis < 3; axis++ {
+		ax := b.Axis(axis)
+		invD := 1 / direction[axis]
+
+		t0 := (ax.min - origin[axis]) * invD
+		t1 := (ax.max - origin[axis]) * invD
+		if invD < 0 {
+			t0, t1 = t1, t0
+		}
+		// Widen the exit distance by the worst-case rounding error of the computation, so
+		// rays through a point on the box surface, like a vertex shared by two triangles
+		// in different nodes, are never culled.
+		t1 *= 1 + 2*slabErrorBound
+
+		if t0 > rayT.min {
+			rayT.min = t0
+		}
+		if t1 < rayT.max {
+			rayT.max = t1
+		}
+		if rayT.max <= rayT.min {
+			return rayT, false
+		}
+	}
+	return rayT, true
+}
+
+func (b *AABB) padToMinimums() {
+	// Adjust the AABB so that no side is narrower than some delta, padding if necessary.
+	delta := 0.0001
+	if b.x.Size() < delta {
+		b.x = b.x.Expand(delta)
+	}
+	if b.y.Size() < delta {
+		b.y = b.y.Expand(delta)
+	}
+	if b.z.Size() < delta {
+		b.z = b.z.Expand(delta)
+	}
+}
@@ -0,0 +1,234 @@
+package vec3
+
+import "sort"
+
+const (
+	bvhBins        = 16  // Number of centroid buckets evaluated per axis by the SAH
+	bvhMaxLeafSize = 4   // Largest object count that may be kept in a single leaf
+	bvhTraversal   = 0.5 // Cost of visiting a node relative to one object intersection
+)
+
+// BVHNode is a node of a bounding volume hierarchy. Its children are either further
+// nodes or the leaf objects themselves.
+type BVHNode struct {
+	left  Hittable
+	right Hittable
+	bbox  AABB
+}
+
+// bvhPrimitive is an object stored in a BVH, tagged with its index in the source list
+// so hits report the same object ID as the list would.
+type bvhPrimitive struct {
+	Hittable
+	id int
+}
+
+// bvhLeaf holds the objects of a leaf that was not worth splitting further.
+type bvhLeaf []Hittable
+
+// bvhWithUnbounded pairs a hierarchy with the objects that have no finite bounding box,
+// like planes, which cannot be placed in it and are tested on every ray instead.
+type bvhWithUnbounded struct {
+	bounded   Hittable
+	unbounded bvhLeaf
+}
+
+// NewBVH builds a bounding volume hierarchy over the objects of list, splitting each
+// node with the surface area heuristic. The list itself is left untouched.
+func NewBVH(list HittableList) Hittable {
+	var objects []Hittable
+	var unbounded bvhLeaf
+	for i, obj := range list.Hittables {
+		if obj.BoundingBox().IsBounded() {
+			objects = append(objects, bvhPrimitive{obj, i})
+		} else {
+			unbounded = append(unbounded, bvhPrimitive{obj, i})
+		}
+	}
+
+	var bvh Hittable = bvhLeaf(nil)
+	if len(objects) > 0 {
+		bvh = buildBVH(objects)
+	}
+	if len(unbounded) == 0 {
+		return bvh
+	}
+	return bvhWithUnbounded{bvh, unbounded}
+}
+
+func (b bvhWithUnbounded) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	hitBounded, rec := b.bounded.Hit(r, rayT, rng)
+	if hitBounded {
+		rayT = NewInterval(rayT.Min(), rec.T())
+	}
+	if hitUnbounded, recUnbounded := b.unbounded.Hit(r, rayT, rng); hitUnbounded {
+		return true, recUnbounded
+	}
+	return hitBounded, rec
+}
+
+func (b bvhWithUnbounded) BoundingBox() AABB { return UniverseAABB() }
+
+func (p bvhPrimitive) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	isHit, rec := p.Hittable.Hit(r, rayT, rng)
+	rec.objectID = p.id
+	return isHit, rec
+}
+
+func (l bvhLeaf) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	hitAnything := false
+	rec := Hit{}
+	for _, obj := range l {
+		if isHit, hit := obj.Hit(r, rayT, rng); isHit {
+			hitAnything = true
+			rayT = NewInterval(rayT.Min(), hit.T())
+			rec = hit
+		}
+	}
+	return hitAnything, rec
+}
+
+func (l bvhLeaf) BoundingBox() AABB {
+	return HittableList{l}.BoundingBox()
+}
+
+func (n BVHNode) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	if !n.bbox.Hit(r, rayT) {
+		return false, Hit{}
+	}
+
+	hitLeft, recLeft := n.left.Hit(r, rayT, rng)
+	if hitLeft {
+		rayT = NewInterval(rayT.Min(), recLeft.T())
+	}
+	hitRight, recRight := n.right.Hit(r, rayT, rng)
+	if hitRight {
+		return true, recRight
+	}
+	return hitLeft, recLeft
+}
+
+func (n BVHNode) BoundingBox() AABB { return n.bbox }
+
+func buildBVH(objects []Hittable) Hittable {
+	if len(objects) == 1 {
+		return objects[0]
+	}
+
+	bbox := EmptyAABB()
+	centroids := EmptyAABB()
+	for _, obj := range objects {
+		b := obj.BoundingBox()
+		bbox = NewAABBEnclosing(bbox, b)
+		c := b.Centroid()
+		centroids = NewAABBEnclosing(centroids, AABB{NewInterval(c.x, c.x), NewInterval(c.y, c.y), NewInterval(c.z, c.z)})
+	}
+
+	axis, split, cost := bestSAHSplit(objects, bbox, centroids)
+	if len(objects) <= bvhMaxLeafSize && cost >= float64(len(objects)) {
+		return bvhLeaf(objects)
+	}
+
+	var mid int
+	if axis < 0 {
+		// All centroids coincide, so no bucket split exists; fall back to halving the list.
+		mid = len(objects) / 2
+	} else {
+		mid = partition(objects, func(h Hittable) bool {
+			return bucketOf(h, axis, centroids) < split
+		})
+	}
+	if mid == 0 || mid == len(objects) {
+		axis = centroids.LongestAxis()
+		sort.SliceStable(objects, func(a, b int) bool {
+			return centroidOf(objects[a], axis) < centroidOf(objects[b], axis)
+		})
+		mid = len(objects) / 2
+	}
+
+	return BVHNode{
+		left:  buildBVH(objects[:mid]),
+		right: buildBVH(objects[mid:]),
+		bbox:  bbox,
+	}
+}
+
+func bestSAHSplit(objects []Hittable, bbox AABB, centroids AABB) (int, int, float64) {
+	// Returns the axis and bucket index of the cheapest split along with its cost, in units
+	// of object intersections. The axis is -1 when the centroids cannot be separated.
+	bestAxis, bestSplit, bestCost := -1, 0, float64(len(objects))
+	area := bbox.SurfaceArea()
+	if area <= 0 {
+		return bestAxis, bestSplit, bestCost
+	}
+
+	for axis := 0; axis < 3; axis++ {
+		if centroids.Axis(axis).Size() <= 0 {
+			continue
+		}
+
+		var counts [bvhBins]int
+		var bounds [bvhBins]AABB
+		for b := range bounds {
+			bounds[b] = EmptyAABB()
+		}
+		for _, obj := range objects {
+			b := bucketOf(obj, axis, centroids)
+			counts[b]++
+			bounds[b] = NewAABBEnclosing(bounds[b], obj.BoundingBox())
+		}
+
+		// Sweep from the right to collect the cost of everything above each split.
+		var rightArea [bvhBins]float64
+		var rightCount [bvhBins]int
+		acc, n := EmptyAABB(), 0
+		for b := bvhBins - 1; b > 0; b-- {
+			acc = NewAABBEnclosing(acc, bounds[b])
+			n += counts[b]
+			rightArea[b] = acc.SurfaceArea()
+			rightCount[b] = n
+		}
+
+		acc, n = EmptyAABB(), 0
+		for split := 1; split < bvhBins; split++ {
+			acc = NewAABBEnclosing(acc, bounds[split-1])
+			n += counts[split-1]
+			if n == 0 || rightCount[split] == 0 {
+				continue
+			}
+			cost := bvhTraversal + (acc.SurfaceArea()*float64(n)+rightArea[split]*float64(rightCount[split]))/area
+			if cost < bestCost {
+				bestAxis, bestSplit, bestCost = axis, split, cost
+			}
+		}
+	}
+	return bestAxis, bestSplit, bestCost
+}
+
+func bucketOf(h Hittable, axis int, centroids AABB) int {
+	ax := centroids.Axis(axis)
+	b := int(bvhBins * (centroidOf(h, axis) - ax.min) / ax.Size())
+	if b >= bvhBins {
+		b = bvhBins - 1
+	}
+	if b < 0 {
+		b = 0
+	}
+	return b
+}
+
+func centroidOf(h Hittable, axis int) float64 {
+	return h.BoundingBox().Centroid().Axis(axis)
+}
+
+func partition(objects []Hittable, left func(Hittable) bool) int {
+	// Reorders objects so those satisfying left come first, and returns how many there are.
+	mid := 0
+	for i, obj := range objects {
+		if left(obj) {
+			objects[i], objects[mid] = objects[mid], objects[i]
+			mid++
+		}
+	}
+	return mid
+}
@@ -18,27 +18,38 @@ func linearToGamma(linearComponent float64) float64 {
 	return math.Sqrt(linearComponent)
 }
 
+// SRGBToLinear decodes an sRGB encoded component in [0, 1] to a linear value.
+func SRGBToLinear(x float64) float64 {
+	if x <= 0.04045 {
+		return x / 12.92
+	}
+	return math.Pow((x+0.055)/1.055, 2.4)
+}
+
 func (c Color) Write(samplesPerPixel int) string {
-	r := c.X()
-	g := c.Y()
-	b := c.Z()
+	r, g, b := c.Quantize(samplesPerPixel, 256, GammaToneMapper{})
+	return fmt.Sprintf("%d %d %d\n", r, g, b)
+}
 
+// Quantize averages the color over samplesPerPixel, tone maps it with tm and returns
+// each component as an integer in [0, levels).
+func (c Color) Quantize(samplesPerPixel int, levels int, tm ToneMapper) (int, int, int) {
 	// Divide the color by the number of samples.
 	scale := 1.0 / float64(samplesPerPixel)
-	r = r * scale
-	g = g * scale
-	b = b * scale
-
-	// Apply the linear to gamma transform
-	r = linearToGamma(r)
-	g = linearToGamma(g)
-	b = linearToGamma(b)
-
-	// Write the translated [0,255] value of each color component.
-	intensity := NewInterval(0.000, 0.999)
-	icr := 256 * intensity.clamp(r)
-	icg := 256 * intensity.clamp(g)
-	icb := 256 * intensity.clamp(b)
-	s := fmt.Sprintf("%d %d %d\n", int(icr), int(icg), int(icb))
-	return s
+	mapped := tm.Map(NewColor(c.X()*scale, c.Y()*scale, c.Z()*scale))
+
+	// Translate each color component to [0,levels).
+	return quantize(mapped.X(), levels), quantize(mapped.Y(), levels), quantize(mapped.Z(), levels)
+}
+
+func quantize(x float64, levels int) int {
+	intensity := NewInterval(0.000, 1.000)
+	q := int(float64(levels) * intensity.clamp(x))
+	if q >= levels {
+		return levels - 1
+	}
+	if q < 0 {
+		return 0
+	}
+	return q
 }
@@ -0,0 +1,104 @@
+package vec3
+
+import "math"
+
+// CSGOp selects how a CSG node combines the solids of its two operands.
+type CSGOp int
+
+const (
+	CSGUnion CSGOp = iota
+	CSGIntersection
+	CSGDifference
+)
+
+// CSG is the union, intersection or difference of two solids. The operands must be
+// closed surfaces whose front faces look outward, so that crossing a front face enters
+// the solid and crossing a back face leaves it. Hits on the combined surface keep the
+// material and UVs of the operand that was hit.
+type CSG struct {
+	op   CSGOp
+	a, b Hittable
+	bbox AABB
+}
+
+// maxCSGCrossings bounds the crossings followed along one ray, in case an operand
+// keeps reporting hits at the same distance.
+const maxCSGCrossings = 256
+
+func NewUnion(a Hittable, b Hittable) CSG {
+	return CSG{CSGUnion, a, b, NewAABBEnclosing(a.BoundingBox(), b.BoundingBox())}
+}
+
+func NewIntersection(a Hittable, b Hittable) CSG {
+	// The intersection lies within both boxes.
+	boxA, boxB := a.BoundingBox(), b.BoundingBox()
+	x := NewInterval(math.Max(boxA.x.min, boxB.x.min), math.Min(boxA.x.max, boxB.x.max))
+	y := NewInterval(math.Max(boxA.y.min, boxB.y.min), math.Min(boxA.y.max, boxB.y.max))
+	z := NewInterval(math.Max(boxA.z.min, boxB.z.min), math.Min(boxA.z.max, boxB.z.max))
+	bbox := NewAABB(x, y, z)
+	if x.min > x.max || y.min > y.max || z.min > z.max {
+		// The operands do not overlap, so nothing can be hit, but keep a valid box.
+		bbox = boxA
+	}
+	return CSG{CSGIntersection, a, b, bbox}
+}
+
+// NewDifference returns the solid a with the solid b cut away. Where the surface of b
+// bounds the result it faces into b, so a sphere minus a sphere gives a correct lens.
+func NewDifference(a Hittable, b Hittable) CSG {
+	return CSG{CSGDifference, a, b, a.BoundingBox()}
+}
+
+func (c CSG) inside(inA bool, inB bool) bool {
+	switch c.op {
+	case CSGUnion:
+		return inA || inB
+	case CSGIntersection:
+		return inA && inB
+	default:
+		return inA && !inB
+	}
+}
+
+func (c CSG) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	// Find the first crossing of each operand past the start of the interval. The
+	// crossings are searched without an upper limit, since a back face beyond the
+	// interval still tells that the ray starts inside.
+	beyond := NewInterval(rayT.Min(), math.Inf(1))
+	hitA, recA := c.a.Hit(r, beyond, rng)
+	hitB, recB := c.b.Hit(r, beyond, rng)
+	inA := hitA && !recA.FrontFace()
+	inB := hitB && !recB.FrontFace()
+
+	// Walk the crossings of both operands in order, until the combined solid is
+	// entered or left.
+	for i := 0; i < maxCSGCrossings && (hitA || hitB); i++ {
+		fromA := hitA && (!hitB || recA.T() <= recB.T())
+		rec := recB
+		if fromA {
+			rec = recA
+		}
+		if rec.T() >= rayT.Max() {
+			break
+		}
+
+		wasInside := c.inside(inA, inB)
+		if fromA {
+			inA = recA.FrontFace()
+			hitA, recA = c.a.Hit(r, NewInterval(rec.T(), math.Inf(1)), rng)
+		} else {
+			inB = recB.FrontFace()
+			hitB, recB = c.b.Hit(r, NewInterval(rec.T(), math.Inf(1)), rng)
+		}
+
+		if isInside := c.inside(inA, inB); isInside != wasInside {
+			// The normal already faces the ray; only the side of the combined solid the
+			// ray arrives from decides the front face, which flips for cut surfaces.
+			rec.frontFace = isInside
+			return true, rec
+		}
+	}
+	return false, Hit{}
+}
+
+func (c CSG) BoundingBox() AABB { return c.bbox }
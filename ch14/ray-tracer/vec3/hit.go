@@ -6,6 +6,10 @@ type Hit struct {
 	mat       Material
 	t         float64
 	frontFace bool
+	u, v      float64 // Surface coordinates of the hit point
+	color     Color   // Interpolated vertex color, when hasColor is set
+	hasColor  bool
+	objectID  int // Index of the hit object in the outermost list searched
 }
 
 func NewHit(p Point3, normal Vec3, t float64) Hit {
@@ -17,7 +21,20 @@ func (h Hit) Normal() Vec3              { return h.normal }
 func (h Hit) T() float64                { return h.t }
 func (h Hit) FrontFace() bool           { return h.frontFace }
 func (h Hit) Material() Material        { return h.mat }
+func (h Hit) ObjectID() int             { return h.objectID }
+func (h Hit) U() float64                { return h.u }
+func (h Hit) V() float64                { return h.v }
 func (h *Hit) SetMaterial(mat Material) { h.mat = mat }
+func (h *Hit) SetUV(u float64, v float64) {
+	h.u = u
+	h.v = v
+}
+
+func (h Hit) VertexColor() (Color, bool) {
+	// Returns the color interpolated from the vertices of the surface that was hit, and
+	// whether the surface has vertex colors at all.
+	return h.color, h.hasColor
+}
 
 func (h *Hit) SetFaceNormal(r Ray, outwardNormal Vec3) {
 	// Sets the hit record normal vector.
@@ -31,8 +48,23 @@ func (h *Hit) SetFaceNormal(r Ray, outwardNormal Vec3) {
 	}
 }
 
+func (h *Hit) SetShadingNormal(shadingNormal Vec3) {
+	// Replaces the normal used for shading, such as an interpolated vertex normal, while
+	// keeping it on the side of the surface the ray arrived from. SetFaceNormal must have
+	// been called with the geometric normal first.
+	// NOTE: the parameter 'shadingNormal' is assumed to have unit length.
+
+	if Dot(shadingNormal, h.normal) < 0 {
+		shadingNormal = shadingNormal.Inv()
+	}
+	h.normal = shadingNormal
+}
+
+// Hittable is an object rays can hit. Objects that sample randomly, such as volumes,
+// draw from rng, the random stream of the camera sample being traced.
 type Hittable interface {
-	Hit(r Ray, rayT Interval) (bool, Hit)
+	Hit(r Ray, rayT Interval, rng RNG) (bool, Hit)
+	BoundingBox() AABB
 }
 
 type HittableList struct {
@@ -50,19 +82,28 @@ func (lst HittableList) Clear() {
 	lst.Hittables = nil
 }
 
-func (lst HittableList) Hit(r Ray, rayT Interval) (bool, Hit) {
+func (lst HittableList) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
 	hitAnything := false
 	closestSoFar := rayT.Max()
 	rec := Hit{}
 
-	for _, obj := range lst.Hittables {
-		isHit, hit := obj.Hit(r, NewInterval(rayT.Min(), closestSoFar))
+	for i, obj := range lst.Hittables {
+		isHit, hit := obj.Hit(r, NewInterval(rayT.Min(), closestSoFar), rng)
 		if isHit {
 			hitAnything = true
 			closestSoFar = hit.T()
 			rec = hit
+			rec.objectID = i
 		}
 	}
 
 	return hitAnything, rec
 }
+
+func (lst HittableList) BoundingBox() AABB {
+	bbox := EmptyAABB()
+	for _, obj := range lst.Hittables {
+		bbox = NewAABBEnclosing(bbox, obj.BoundingBox())
+	}
+	return bbox
+}
@@ -0,0 +1,229 @@
+package vec3
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+// WrapMode selects how an image texture is continued outside UVs in [0, 1].
+type WrapMode int
+
+const (
+	WrapRepeat WrapMode = iota // Tile the image
+	WrapClamp                  // Extend the edge texels
+	WrapMirror                 // Tile the image, flipping every other copy
+)
+
+// ImageTexture is an image mapped onto the unit square of UVs, with u running left to
+// right and v bottom to top. The texels are stored as linear colors and filtered
+// bilinearly.
+type ImageTexture struct {
+	width, height int
+	texels        []Color
+	wrap          WrapMode
+}
+
+// NewImageTexture returns a texture of img, whose colors are taken to be sRGB encoded.
+func NewImageTexture(img image.Image, wrap WrapMode) *ImageTexture {
+	bounds := img.Bounds()
+	t := &ImageTexture{
+		width:  bounds.Dx(),
+		height: bounds.Dy(),
+		texels: make([]Color, bounds.Dx()*bounds.Dy()),
+		wrap:   wrap,
+	}
+
+	// Decode each 8 or 16 bit level once, rather than for every lookup.
+	for y := 0; y < t.height; y++ {
+		for x := 0; x < t.width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			t.texels[y*t.width+x] = NewColor(
+				SRGBToLinear(float64(r)/0xffff),
+				SRGBToLinear(float64(g)/0xffff),
+				SRGBToLinear(float64(b)/0xffff))
+		}
+	}
+	return t
+}
+
+// LoadImageTexture reads a PNG, JPEG or PPM image into a texture.
+func LoadImageTexture(path string, wrap WrapMode) (*ImageTexture, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	var img image.Image
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte("P3")) || bytes.Equal(magic, []byte("P6")) {
+		img, err = decodePPM(br)
+	} else {
+		img, _, err = image.Decode(br)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewImageTexture(img, wrap), nil
+}
+
+func (t *ImageTexture) Width() int  { return t.width }
+func (t *ImageTexture) Height() int { return t.height }
+
+func (t *ImageTexture) Value(u float64, v float64, p Point3) Color {
+	// If we have no texture data, then return solid cyan as a debugging aid.
+	if t.width == 0 || t.height == 0 {
+		return NewColor(0, 1, 1)
+	}
+
+	// Find the four texels around the lookup, with texel centers at half-integer
+	// coordinates and image rows running from the top down.
+	x := u*float64(t.width) - 0.5
+	y := (1-v)*float64(t.height) - 0.5
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	i0, i1 := t.wrapIndex(int(x0), t.width), t.wrapIndex(int(x0)+1, t.width)
+	j0, j1 := t.wrapIndex(int(y0), t.height), t.wrapIndex(int(y0)+1, t.height)
+
+	top := t.texel(i0, j0).Mul(1 - fx).Add(t.texel(i1, j0).Mul(fx))
+	bottom := t.texel(i0, j1).Mul(1 - fx).Add(t.texel(i1, j1).Mul(fx))
+	c := top.Mul(1 - fy).Add(bottom.Mul(fy))
+	return NewColor(c.x, c.y, c.z)
+}
+
+func (t *ImageTexture) texel(i int, j int) Color {
+	return t.texels[j*t.width+i]
+}
+
+// wrapIndex maps a texel index along an axis of n texels into the image.
+func (t *ImageTexture) wrapIndex(i int, n int) int {
+	switch t.wrap {
+	case WrapClamp:
+		return min(max(i, 0), n-1)
+	case WrapMirror:
+		period := 2 * n
+		i %= period
+		if i < 0 {
+			i += period
+		}
+		if i >= n {
+			i = period - 1 - i
+		}
+		return i
+	default:
+		i %= n
+		if i < 0 {
+			i += n
+		}
+		return i
+	}
+}
+
+// decodePPM decodes an ASCII (P3) or binary (P6) PPM image.
+func decodePPM(r *bufio.Reader) (image.Image, error) {
+	var header [4]string
+	for i := range header {
+		token, err := ppmToken(r)
+		if err != nil {
+			return nil, fmt.Errorf("ppm: reading header: %w", err)
+		}
+		header[i] = token
+	}
+	magic := header[0]
+	width, errW := strconv.Atoi(header[1])
+	height, errH := strconv.Atoi(header[2])
+	maxVal, errM := strconv.Atoi(header[3])
+	if err := errors.Join(errW, errH, errM); err != nil {
+		return nil, fmt.Errorf("ppm: reading header: %w", err)
+	}
+	if width <= 0 || height <= 0 || maxVal <= 0 || maxVal > 65535 || width*height > 1<<28 {
+		return nil, fmt.Errorf("ppm: invalid header %dx%d, max %d", width, height, maxVal)
+	}
+
+	img := image.NewRGBA64(image.Rect(0, 0, width, height))
+	levels := make([]int, 3*width*height)
+	switch magic {
+	case "P3":
+		for i := range levels {
+			token, err := ppmToken(r)
+			if err == nil {
+				levels[i], err = strconv.Atoi(token)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("ppm: reading sample %d: %w", i, err)
+			}
+		}
+	case "P6":
+		// A single whitespace byte separates the header from the samples, which take
+		// two bytes each, big-endian, when the maximum is above 255.
+		if _, err := r.ReadByte(); err != nil {
+			return nil, fmt.Errorf("ppm: %w", err)
+		}
+		size := 1
+		if maxVal > 255 {
+			size = 2
+		}
+		buf := make([]byte, size*len(levels))
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("ppm: reading samples: %w", err)
+		}
+		for i := range levels {
+			if size == 2 {
+				levels[i] = int(buf[2*i])<<8 | int(buf[2*i+1])
+			} else {
+				levels[i] = int(buf[i])
+			}
+		}
+	default:
+		return nil, fmt.Errorf("ppm: unsupported format %q", magic)
+	}
+
+	for i := 0; i < width*height; i++ {
+		off := 4 * i
+		for c := 0; c < 3; c++ {
+			level := min(max(levels[3*i+c], 0), maxVal) * 0xffff / maxVal
+			img.Pix[2*(off+c)] = uint8(level >> 8)
+			img.Pix[2*(off+c)+1] = uint8(level)
+		}
+		img.Pix[2*(off+3)] = 0xff
+		img.Pix[2*(off+3)+1] = 0xff
+	}
+	return img, nil
+}
+
+// ppmToken returns the next whitespace separated token of a PPM file, skipping comments
+// that run from '#' to the end of the line.
+func ppmToken(r *bufio.Reader) (string, error) {
+	var token []byte
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF && len(token) > 0 {
+			return string(token), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case c == '#' && len(token) == 0:
+			if _, err := r.ReadString('\n'); err != nil {
+				return "", err
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if len(token) > 0 {
+				// Leave the separator after the last header token for the caller.
+				return string(token), r.UnreadByte()
+			}
+		default:
+			token = append(token, c)
+		}
+	}
+}
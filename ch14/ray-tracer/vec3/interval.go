@@ -35,3 +35,17 @@ func (i Interval) clamp(x float64) float64 {
 	}
 	return x
 }
+
+func NewIntervalEnclosing(a Interval, b Interval) Interval {
+	// Create the interval tightly enclosing the two input intervals.
+	return Interval{math.Min(a.min, b.min), math.Max(a.max, b.max)}
+}
+
+func (i Interval) Size() float64 {
+	return i.max - i.min
+}
+
+func (i Interval) Expand(delta float64) Interval {
+	padding := delta / 2
+	return Interval{i.min - padding, i.max + padding}
+}
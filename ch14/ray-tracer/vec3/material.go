@@ -3,43 +3,69 @@ package vec3
 import "math"
 
 type Material interface {
-	Scatter(rIn Ray, rec Hit) (bool, Ray, Color)
+	Scatter(rIn Ray, rec Hit, rng RNG) (bool, Ray, Color)
 }
 
 type Lambertian struct {
-	albedo Color
+	tex Texture
 }
 
 func NewLambertian(albedo Color) Lambertian {
-	return Lambertian{albedo: albedo}
+	return Lambertian{tex: NewSolidColor(albedo)}
 }
 
-func (l Lambertian) Scatter(rIn Ray, rec Hit) (bool, Ray, Color) {
-	scatterDirection := rec.Normal().Add(RandomUnitVector())
+func NewLambertianTexture(tex Texture) Lambertian {
+	return Lambertian{tex: tex}
+}
+
+func (l Lambertian) Scatter(rIn Ray, rec Hit, rng RNG) (bool, Ray, Color) {
+	scatterDirection := rec.Normal().Add(RandomUnitVector(rng))
 
 	// Catch degenerate scatter direction
 	if scatterDirection.NearZero() {
 		scatterDirection = rec.Normal()
 	}
 
-	scattered := NewRay(rec.P(), scatterDirection)
-	attenuation := l.albedo
+	scattered := NewRayAtTime(rec.P(), scatterDirection, rIn.Time())
+	attenuation := l.tex.Value(rec.U(), rec.V(), rec.P())
 	return true, scattered, attenuation
 }
 
+// VertexColorLambertian is a diffuse material whose albedo is the vertex color of the
+// surface it is hit on, or a fixed fallback albedo where the surface has none.
+type VertexColorLambertian struct {
+	fallback Color
+}
+
+func NewVertexColorLambertian(fallback Color) VertexColorLambertian {
+	return VertexColorLambertian{fallback: fallback}
+}
+
+func (l VertexColorLambertian) Scatter(rIn Ray, rec Hit, rng RNG) (bool, Ray, Color) {
+	albedo, ok := rec.VertexColor()
+	if !ok {
+		albedo = l.fallback
+	}
+	return NewLambertian(albedo).Scatter(rIn, rec, rng)
+}
+
 type Metal struct {
-	albedo Color
-	fuzz   float64
+	tex  Texture
+	fuzz float64
 }
 
 func NewMetal(albedo Color, fuzz float64) Metal {
-	return Metal{albedo: albedo, fuzz: fuzz}
+	return Metal{tex: NewSolidColor(albedo), fuzz: fuzz}
+}
+
+func NewMetalTexture(tex Texture, fuzz float64) Metal {
+	return Metal{tex: tex, fuzz: fuzz}
 }
 
-func (m Metal) Scatter(rIn Ray, rec Hit) (bool, Ray, Color) {
+func (m Metal) Scatter(rIn Ray, rec Hit, rng RNG) (bool, Ray, Color) {
 	reflected := Reflect(UnitVector(rIn.Direction()), rec.Normal())
-	scattered := NewRay(rec.P(), reflected.Add(RandomUnitVector().Mul(m.fuzz)))
-	attenuation := m.albedo
+	scattered := NewRayAtTime(rec.P(), reflected.Add(RandomUnitVector(rng).Mul(m.fuzz)), rIn.Time())
+	attenuation := m.tex.Value(rec.U(), rec.V(), rec.P())
 	return Dot(scattered.Direction(), rec.Normal()) > 0, scattered, attenuation
 }
 
@@ -51,7 +77,7 @@ func NewDielectric(indexOfRefraction float64) Dielectric {
 	return Dielectric{indexOfRefraction}
 }
 
-func (d Dielectric) Scatter(rIn Ray, rec Hit) (bool, Ray, Color) {
+func (d Dielectric) Scatter(rIn Ray, rec Hit, rng RNG) (bool, Ray, Color) {
 	attenuation := NewColor(1.0, 1.0, 1.0)
 	var refractionRatio float64
 	if rec.FrontFace() {
@@ -67,13 +93,13 @@ func (d Dielectric) Scatter(rIn Ray, rec Hit) (bool, Ray, Color) {
 	cannotRefract := refractionRatio*sinTheta > 1.0
 	var direction Vec3
 
-	if cannotRefract || reflectance(cosTheta, refractionRatio) > Random() {
+	if cannotRefract || reflectance(cosTheta, refractionRatio) > Random(rng) {
 		direction = Reflect(unitDirection, rec.Normal())
 	} else {
 		direction = Refract(unitDirection, rec.Normal(), refractionRatio)
 	}
 
-	scattered := NewRay(rec.P(), direction)
+	scattered := NewRayAtTime(rec.P(), direction, rIn.Time())
 	return true, scattered, attenuation
 }
 
@@ -83,3 +109,23 @@ func reflectance(cosine float64, refIdx float64) float64 {
 	r0 = r0 * r0
 	return r0 + (1-r0)*math.Pow((1-cosine), 5)
 }
+
+// Isotropic is the phase function of a participating medium, which scatters light
+// equally in all directions.
+type Isotropic struct {
+	tex Texture
+}
+
+func NewIsotropic(albedo Color) Isotropic {
+	return Isotropic{tex: NewSolidColor(albedo)}
+}
+
+func NewIsotropicTexture(tex Texture) Isotropic {
+	return Isotropic{tex: tex}
+}
+
+func (i Isotropic) Scatter(rIn Ray, rec Hit, rng RNG) (bool, Ray, Color) {
+	scattered := NewRayAtTime(rec.P(), RandomUnitVector(rng), rIn.Time())
+	attenuation := i.tex.Value(rec.U(), rec.V(), rec.P())
+	return true, scattered, attenuation
+}
@@ -0,0 +1,117 @@
+package vec3
+
+import "fmt"
+
+// TriangleMesh is an indexed triangle mesh. Its vertices, with their optional normals
+// and UVs, are stored once and shared by every triangle that uses them.
+type TriangleMesh struct {
+	positions []Point3
+	normals   []Vec3
+	uvs       []UV
+	colors    []Color
+	indices   []int
+	mat       Material
+	bvh       Hittable
+}
+
+// MeshTriangle is one triangle of a TriangleMesh. Placing the triangles of a mesh
+// individually into a HittableList or BVH renders the same as the mesh itself.
+type MeshTriangle struct {
+	mesh  *TriangleMesh
+	index int
+}
+
+// NewTriangleMesh builds a mesh from three vertex indices per triangle. Normals and
+// uvs are either empty or hold one entry per position; when normals are given they are
+// interpolated across each triangle for shading.
+func NewTriangleMesh(positions []Point3, normals []Vec3, uvs []UV, indices []int, material Material) (*TriangleMesh, error) {
+	if len(indices)%3 != 0 {
+		return nil, fmt.Errorf("mesh has %d indices, not a multiple of 3", len(indices))
+	}
+	if len(normals) != 0 && len(normals) != len(positions) {
+		return nil, fmt.Errorf("mesh has %d normals for %d positions", len(normals), len(positions))
+	}
+	if len(uvs) != 0 && len(uvs) != len(positions) {
+		return nil, fmt.Errorf("mesh has %d UVs for %d positions", len(uvs), len(positions))
+	}
+	for _, i := range indices {
+		if i < 0 || i >= len(positions) {
+			return nil, fmt.Errorf("mesh index %d out of range [0, %d)", i, len(positions))
+		}
+	}
+
+	m := &TriangleMesh{positions: positions, normals: normals, uvs: uvs, indices: indices, mat: material}
+	m.bvh = NewBVH(HittableList{m.Triangles()})
+	return m, nil
+}
+
+func (m *TriangleMesh) NumTriangles() int { return len(m.indices) / 3 }
+
+// SetVertexColors gives every vertex a color, which hits report interpolated across
+// each triangle. Materials such as VertexColorLambertian use them.
+func (m *TriangleMesh) SetVertexColors(colors []Color) error {
+	if len(colors) != len(m.positions) {
+		return fmt.Errorf("mesh has %d colors for %d positions", len(colors), len(m.positions))
+	}
+	m.colors = colors
+	return nil
+}
+
+// Triangles returns a hittable for every triangle of the mesh, in order.
+func (m *TriangleMesh) Triangles() []Hittable {
+	tris := make([]Hittable, m.NumTriangles())
+	for i := range tris {
+		tris[i] = MeshTriangle{m, i}
+	}
+	return tris
+}
+
+func (m *TriangleMesh) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	return m.bvh.Hit(r, rayT, rng)
+}
+
+func (m *TriangleMesh) BoundingBox() AABB { return m.bvh.BoundingBox() }
+
+func (tri MeshTriangle) vertices() (int, int, int) {
+	i := 3 * tri.index
+	return tri.mesh.indices[i], tri.mesh.indices[i+1], tri.mesh.indices[i+2]
+}
+
+func (tri MeshTriangle) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	m := tri.mesh
+	i0, i1, i2 := tri.vertices()
+	v0, v1, v2 := m.positions[i0], m.positions[i1], m.positions[i2]
+
+	ok, t, b0, b1, b2 := intersectTriangle(r, rayT, v0, v1, v2)
+	if !ok {
+		return false, Hit{}
+	}
+
+	p := barycentric(v0.Vec3, v1.Vec3, v2.Vec3, b0, b1, b2)
+	hitRecord := NewHit(Point3{p}, Vec3{}, t)
+	hitRecord.SetFaceNormal(r, UnitVector(Cross(v1.Sub(v0.Vec3), v2.Sub(v0.Vec3))))
+	if m.normals != nil {
+		n := barycentric(m.normals[i0], m.normals[i1], m.normals[i2], b0, b1, b2)
+		if !n.NearZero() {
+			hitRecord.SetShadingNormal(UnitVector(n))
+		}
+	}
+	if m.uvs != nil {
+		uv0, uv1, uv2 := m.uvs[i0], m.uvs[i1], m.uvs[i2]
+		hitRecord.SetUV(b0*uv0.U+b1*uv1.U+b2*uv2.U, b0*uv0.V+b1*uv1.V+b2*uv2.V)
+	} else {
+		hitRecord.SetUV(b1, b2)
+	}
+	if m.colors != nil {
+		c := barycentric(m.colors[i0].Vec3, m.colors[i1].Vec3, m.colors[i2].Vec3, b0, b1, b2)
+		hitRecord.color = Color{c}
+		hitRecord.hasColor = true
+	}
+	hitRecord.SetMaterial(m.mat)
+	return true, hitRecord
+}
+
+func (tri MeshTriangle) BoundingBox() AABB {
+	i0, i1, i2 := tri.vertices()
+	return triangleBox(tri.mesh.positions[i0], tri.mesh.positions[i1], tri.mesh.positions[i2])
+}
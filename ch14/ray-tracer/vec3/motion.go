@@ -0,0 +1,185 @@
+package vec3
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Quaternion is a rotation stored as a unit quaternion w + xi + yj + zk.
+type Quaternion struct {
+	w, x, y, z float64
+}
+
+// NewQuaternion returns the rotation by the given angle in degrees around axis, turning
+// the same way as Rotate.
+func NewQuaternion(axis Vec3, degrees float64) Quaternion {
+	a := UnitVector(axis)
+	half := DegreesToRadians(degrees) / 2
+	s := math.Sin(half)
+	return Quaternion{math.Cos(half), a.x * s, a.y * s, a.z * s}
+}
+
+func IdentityQuaternion() Quaternion {
+	return Quaternion{1, 0, 0, 0}
+}
+
+func (q Quaternion) dot(p Quaternion) float64 {
+	return q.w*p.w + q.x*p.x + q.y*p.y + q.z*p.z
+}
+
+// Slerp interpolates along the shortest arc from q at t=0 to p at t=1.
+func Slerp(q Quaternion, p Quaternion, t float64) Quaternion {
+	// q and -q are the same rotation; pick the one closer to q to take the short way.
+	cosTheta := q.dot(p)
+	if cosTheta < 0 {
+		p = Quaternion{-p.w, -p.x, -p.y, -p.z}
+		cosTheta = -cosTheta
+	}
+
+	// Fall back to normalized linear interpolation when the rotations nearly coincide,
+	// where the sine below vanishes.
+	var a, b float64
+	if cosTheta > 0.9995 {
+		a, b = 1-t, t
+	} else {
+		theta := math.Acos(cosTheta)
+		sinTheta := math.Sin(theta)
+		a, b = math.Sin((1-t)*theta)/sinTheta, math.Sin(t*theta)/sinTheta
+	}
+	r := Quaternion{a*q.w + b*p.w, a*q.x + b*p.x, a*q.y + b*p.y, a*q.z + b*p.z}
+	n := math.Sqrt(r.dot(r))
+	return Quaternion{r.w / n, r.x / n, r.y / n, r.z / n}
+}
+
+// Transform returns the rotation as a transform.
+func (q Quaternion) Transform() Transform {
+	w, x, y, z := q.w, q.x, q.y, q.z
+	m := IdentityMat4()
+	m[0][0], m[0][1], m[0][2] = 1-2*(y*y+z*z), 2*(x*y-w*z), 2*(x*z+w*y)
+	m[1][0], m[1][1], m[1][2] = 2*(x*y+w*z), 1-2*(x*x+z*z), 2*(y*z-w*x)
+	m[2][0], m[2][1], m[2][2] = 2*(x*z-w*y), 2*(y*z+w*x), 1-2*(x*x+y*y)
+	return Transform{m, m.Transpose()}
+}
+
+// Keyframe is the placement of an animated instance at a point in time, applied as
+// scale, then rotation, then translation. No component of Scale may be zero, so the
+// zero Keyframe is not valid; use New(1, 1, 1) to keep the size of the object.
+type Keyframe struct {
+	Time        float64
+	Translation Vec3
+	Rotation    Quaternion
+	Scale       Vec3
+}
+
+func (k Keyframe) transform() Transform {
+	return Scale(k.Scale).Then(k.Rotation.Transform()).Then(Translate(k.Translation))
+}
+
+// AnimatedInstance places a shared object in the scene through a transform that is
+// interpolated between keyframes at the time of each ray. Before the first and after
+// the last keyframe the object holds still.
+type AnimatedInstance struct {
+	object    Hittable
+	keyframes []Keyframe
+	bbox      AABB
+}
+
+// motionSteps is the number of times each keyframe interval is sampled to bound the
+// object over its motion.
+const motionSteps = 32
+
+var (
+	ErrNoKeyframes     = errors.New("animated instance has no keyframes")
+	ErrDegenerateScale = errors.New("keyframe scale is zero or changes sign between keyframes")
+)
+
+// NewAnimatedInstance returns an instance of object moving through keyframes, which
+// are sorted by time. Its bounding box encloses the object over the whole animation.
+// The scale is interpolated linearly, so each of its components must keep one sign
+// throughout, or the object would collapse to a plane between two keyframes.
+func NewAnimatedInstance(object Hittable, keyframes []Keyframe) (AnimatedInstance, error) {
+	if len(keyframes) == 0 {
+		return AnimatedInstance{}, ErrNoKeyframes
+	}
+	keyframes = append([]Keyframe(nil), keyframes...)
+	sort.SliceStable(keyframes, func(i, j int) bool { return keyframes[i].Time < keyframes[j].Time })
+	for i, k := range keyframes {
+		if k.Scale.x == 0 || k.Scale.y == 0 || k.Scale.z == 0 {
+			return AnimatedInstance{}, ErrDegenerateScale
+		}
+		if i > 0 {
+			prev := keyframes[i-1].Scale
+			if prev.x*k.Scale.x < 0 || prev.y*k.Scale.y < 0 || prev.z*k.Scale.z < 0 {
+				return AnimatedInstance{}, ErrDegenerateScale
+			}
+		}
+	}
+	in := AnimatedInstance{object: object, keyframes: keyframes}
+
+	objectBox := object.BoundingBox()
+	if !objectBox.IsBounded() {
+		in.bbox = UniverseAABB()
+		return in, nil
+	}
+
+	// Enclose the object at closely spaced times. Between two samples a corner of the
+	// object box moves along a short arc, so growing the box by the longest step
+	// covers the path in between as well.
+	in.bbox = in.transformAt(keyframes[0].Time).Box(objectBox)
+	for i := 1; i < len(keyframes); i++ {
+		t0, t1 := keyframes[i-1].Time, keyframes[i].Time
+		prev := in.transformAt(t0)
+		for s := 1; s <= motionSteps; s++ {
+			next := in.transformAt(t0 + (t1-t0)*float64(s)/motionSteps)
+			step := 0.0
+			for c := 0; c < 8; c++ {
+				corner := NewPoint3(objectBox.x.min, objectBox.y.min, objectBox.z.min)
+				if c&1 != 0 {
+					corner.x = objectBox.x.max
+				}
+				if c&2 != 0 {
+					corner.y = objectBox.y.max
+				}
+				if c&4 != 0 {
+					corner.z = objectBox.z.max
+				}
+				step = math.Max(step, next.Point(corner).Sub(prev.Point(corner).Vec3).Length())
+			}
+			box := next.Box(objectBox)
+			box = NewAABB(box.x.Expand(2*step), box.y.Expand(2*step), box.z.Expand(2*step))
+			in.bbox = NewAABBEnclosing(in.bbox, box)
+			prev = next
+		}
+	}
+	return in, nil
+}
+
+// transformAt returns the object to world transform at time tm.
+func (in AnimatedInstance) transformAt(tm float64) Transform {
+	keys := in.keyframes
+	i := sort.Search(len(keys), func(i int) bool { return keys[i].Time > tm })
+	if i == 0 {
+		return keys[0].transform()
+	}
+	if i == len(keys) {
+		return keys[len(keys)-1].transform()
+	}
+
+	// Interpolate translation and scale linearly and rotation along the shortest arc.
+	k0, k1 := keys[i-1], keys[i]
+	s := (tm - k0.Time) / (k1.Time - k0.Time)
+	k := Keyframe{
+		Time:        tm,
+		Translation: k0.Translation.Mul(1 - s).Add(k1.Translation.Mul(s)),
+		Rotation:    Slerp(k0.Rotation, k1.Rotation, s),
+		Scale:       k0.Scale.Mul(1 - s).Add(k1.Scale.Mul(s)),
+	}
+	return k.transform()
+}
+
+func (in AnimatedInstance) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	return hitTransformed(in.object, in.transformAt(r.Time()), r, rayT, rng)
+}
+
+func (in AnimatedInstance) BoundingBox() AABB { return in.bbox }
@@ -0,0 +1,102 @@
+package vec3
+
+import "math"
+
+// NoiseTexture is gray fractal noise, like clouds, at the given frequency.
+type NoiseTexture struct {
+	noise *Perlin
+	scale float64
+}
+
+func NewNoiseTexture(noise *Perlin, scale float64) NoiseTexture {
+	return NoiseTexture{noise, scale}
+}
+
+func (n NoiseTexture) Value(u float64, v float64, p Point3) Color {
+	c := NewColor(0.5, 0.5, 0.5).Mul(1 + n.noise.FBM(Point3{p.Mul(n.scale)}, 7))
+	return NewColor(c.x, c.y, c.z)
+}
+
+// MarbleTexture is gray marble, with veins running across the z axis that turbulence
+// pushes around.
+type MarbleTexture struct {
+	noise *Perlin
+	scale float64
+}
+
+func NewMarbleTexture(noise *Perlin, scale float64) MarbleTexture {
+	return MarbleTexture{noise, scale}
+}
+
+func (m MarbleTexture) Value(u float64, v float64, p Point3) Color {
+	c := NewColor(0.5, 0.5, 0.5).Mul(1 + math.Sin(m.scale*p.z+10*m.noise.Turbulence(p, 7)))
+	return NewColor(c.x, c.y, c.z)
+}
+
+// WoodTexture is wood grain of growth rings around the y axis, blending from the light
+// to the dark color across each ring. Noise warps the rings so they are not perfect
+// circles.
+type WoodTexture struct {
+	noise *Perlin
+	scale float64
+	light Color
+	dark  Color
+}
+
+// NewWoodTexture returns wood with scale rings per unit of distance from the y axis.
+func NewWoodTexture(noise *Perlin, scale float64, light Color, dark Color) WoodTexture {
+	return WoodTexture{noise, scale, light, dark}
+}
+
+func (w WoodTexture) Value(u float64, v float64, p Point3) Color {
+	r := math.Hypot(p.x, p.z)*w.scale + 0.8*w.noise.Noise(NewPoint3(p.x*2, p.y*0.3, p.z*2))
+	ring := r - math.Floor(r)
+
+	// Keep the dark late wood narrow compared to the light early wood.
+	t := math.Pow(0.5+0.5*math.Cos(2*math.Pi*ring), 3)
+	c := w.light.Mul(1 - t).Add(w.dark.Mul(t))
+	return NewColor(c.x, c.y, c.z)
+}
+
+// GraniteTexture is speckled stone of pink, gray and black mineral grains, with one
+// grain per Worley cell at the given frequency and finer Perlin mottling within it.
+type GraniteTexture struct {
+	cells Worley
+	noise *Perlin
+	scale float64
+}
+
+func NewGraniteTexture(cells Worley, noise *Perlin, scale float64) GraniteTexture {
+	return GraniteTexture{cells, noise, scale}
+}
+
+var graniteMinerals = [...]struct {
+	share float64
+	color Color
+}{
+	{0.45, NewColor(0.72, 0.50, 0.45)}, // feldspar
+	{0.40, NewColor(0.65, 0.65, 0.66)}, // quartz
+	{0.15, NewColor(0.06, 0.06, 0.07)}, // mica
+}
+
+func (g GraniteTexture) Value(u float64, v float64, p Point3) Color {
+	scaled := Point3{p.Mul(g.scale)}
+	f1, f2, id := g.cells.Evaluate(scaled)
+
+	// Pick the mineral of the grain from the ID of its cell.
+	pick := unitFloat(id)
+	mineral := graniteMinerals[len(graniteMinerals)-1].color
+	for _, m := range graniteMinerals {
+		if pick < m.share {
+			mineral = m.color
+			break
+		}
+		pick -= m.share
+	}
+
+	// Mottle each grain and darken the boundaries between grains.
+	mottle := 0.85 + 0.15*g.noise.Noise(Point3{scaled.Mul(4)})
+	edge := math.Min(1, (f2-f1)/0.1)
+	c := mineral.Mul(mottle * (0.6 + 0.4*edge))
+	return NewColor(c.x, c.y, c.z)
+}
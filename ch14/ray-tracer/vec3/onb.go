@@ -0,0 +1,44 @@
+package vec3
+
+import "math"
+
+// ONB is an orthonormal basis, used to move between world space and the local frame
+// of a shape or a scattering direction.
+type ONB struct {
+	u, v, w Vec3
+}
+
+// NewONB returns a right-handed basis whose w axis points along n, which need not have
+// unit length.
+func NewONB(n Vec3) ONB {
+	// Duff et al., "Building an Orthonormal Basis, Revisited", 2017.
+	w := UnitVector(n)
+	sign := math.Copysign(1, w.z)
+	a := -1 / (sign + w.z)
+	b := w.x * w.y * a
+	u := New(1+sign*w.x*w.x*a, sign*b, -sign*w.x)
+	v := New(b, sign+w.y*w.y*a, -w.y)
+	return ONB{u, v, w}
+}
+
+// NewONBFromAxes returns the right-handed basis whose w axis points along w and whose
+// u axis points along the part of u perpendicular to w.
+func NewONBFromAxes(w Vec3, u Vec3) ONB {
+	wUnit := UnitVector(w)
+	uUnit := UnitVector(u.Sub(wUnit.Mul(Dot(u, wUnit))))
+	return ONB{uUnit, Cross(wUnit, uUnit), wUnit}
+}
+
+func (o ONB) U() Vec3 { return o.u }
+func (o ONB) V() Vec3 { return o.v }
+func (o ONB) W() Vec3 { return o.w }
+
+// Local returns the world space vector with coordinates a, b, c in the basis.
+func (o ONB) Local(a float64, b float64, c float64) Vec3 {
+	return o.u.Mul(a).Add(o.v.Mul(b)).Add(o.w.Mul(c))
+}
+
+// ToLocal returns the coordinates of the world space vector d in the basis.
+func (o ONB) ToLocal(d Vec3) Vec3 {
+	return New(Dot(d, o.u), Dot(d, o.v), Dot(d, o.w))
+}
@@ -0,0 +1,118 @@
+package vec3
+
+import "math"
+
+const pointCount = 256
+
+// Perlin is a gradient noise generator. Its lattice of random gradients is drawn from
+// an RNG, so the same seed gives the same noise.
+type Perlin struct {
+	randVec [pointCount]Vec3
+	permX   [pointCount]int
+	permY   [pointCount]int
+	permZ   [pointCount]int
+}
+
+func NewPerlin(rng RNG) *Perlin {
+	p := &Perlin{}
+	for i := 0; i < pointCount; i++ {
+		p.randVec[i] = UnitVector(RandomInRangeVec3(rng, -1, 1))
+	}
+	perlinGeneratePerm(rng, &p.permX)
+	perlinGeneratePerm(rng, &p.permY)
+	perlinGeneratePerm(rng, &p.permZ)
+	return p
+}
+
+// Noise returns smooth noise in about [-1, 1] at p, with features about one unit apart.
+func (pn *Perlin) Noise(p Point3) float64 {
+	u := p.x - math.Floor(p.x)
+	v := p.y - math.Floor(p.y)
+	w := p.z - math.Floor(p.z)
+
+	i := int(math.Floor(p.x))
+	j := int(math.Floor(p.y))
+	k := int(math.Floor(p.z))
+	var c [2][2][2]Vec3
+
+	for di := 0; di < 2; di++ {
+		for dj := 0; dj < 2; dj++ {
+			for dk := 0; dk < 2; dk++ {
+				c[di][dj][dk] = pn.randVec[pn.permX[(i+di)&255]^pn.permY[(j+dj)&255]^pn.permZ[(k+dk)&255]]
+			}
+		}
+	}
+
+	return perlinInterp(c, u, v, w)
+}
+
+// Turbulence returns the magnitude of the sum of depth octaves of noise, each at twice
+// the frequency and half the weight of the one before.
+func (pn *Perlin) Turbulence(p Point3, depth int) float64 {
+	accum := 0.0
+	tempP := p
+	weight := 1.0
+
+	for i := 0; i < depth; i++ {
+		accum += weight * pn.Noise(tempP)
+		weight *= 0.5
+		tempP = Point3{tempP.Mul(2)}
+	}
+
+	return math.Abs(accum)
+}
+
+// FBM returns fractional Brownian motion: octaves of noise like Turbulence, but signed
+// and normalized by the total weight, so the result stays in about [-1, 1].
+func (pn *Perlin) FBM(p Point3, octaves int) float64 {
+	accum := 0.0
+	total := 0.0
+	tempP := p
+	weight := 1.0
+
+	for i := 0; i < octaves; i++ {
+		accum += weight * pn.Noise(tempP)
+		total += weight
+		weight *= 0.5
+		tempP = Point3{tempP.Mul(2)}
+	}
+	if total == 0 {
+		return 0
+	}
+	return accum / total
+}
+
+func perlinGeneratePerm(rng RNG, p *[pointCount]int) {
+	for i := 0; i < pointCount; i++ {
+		p[i] = i
+	}
+
+	// Fisher-Yates shuffle.
+	for i := pointCount - 1; i > 0; i-- {
+		target := int(RandomInRange(rng, 0, float64(i+1)))
+		p[i], p[target] = p[target], p[i]
+	}
+}
+
+func perlinInterp(c [2][2][2]Vec3, u float64, v float64, w float64) float64 {
+	// Hermite cubic smoothing of the weights hides the lattice.
+	uu := u * u * (3 - 2*u)
+	vv := v * v * (3 - 2*v)
+	ww := w * w * (3 - 2*w)
+	accum := 0.0
+
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			for k := 0; k < 2; k++ {
+				fi, fj, fk := float64(i), float64(j), float64(k)
+				weightV := New(u-fi, v-fj, w-fk)
+				accum += (fi*uu + (1-fi)*(1-uu)) *
+					(fj*vv + (1-fj)*(1-vv)) *
+					(fk*ww + (1-fk)*(1-ww)) *
+					Dot(c[i][j][k], weightV)
+			}
+		}
+	}
+
+	return accum
+}
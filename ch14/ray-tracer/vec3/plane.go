@@ -0,0 +1,115 @@
+package vec3
+
+import (
+	"fmt"
+	"math"
+)
+
+// Plane is an infinite plane through a point. Its bounding box is unbounded, so a BVH
+// keeps it outside the hierarchy and tests it on every ray.
+type Plane struct {
+	point Point3
+	frame ONB
+	mat   Material
+}
+
+// NewPlane returns the plane through point whose front face looks along normal. Hits
+// report the coordinates of the hit point along two axes in the plane as UVs.
+func NewPlane(point Point3, normal Vec3, material Material) Plane {
+	return Plane{point, NewONB(normal), material}
+}
+
+func (pl Plane) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	normal := pl.frame.W()
+	denom := Dot(normal, r.Direction())
+
+	// No hit if the ray is parallel to the plane.
+	if math.Abs(denom) < 1e-8 {
+		return false, Hit{}
+	}
+	t := Dot(normal, pl.point.Sub(r.Origin().Vec3)) / denom
+	if !rayT.Surrounds(t) {
+		return false, Hit{}
+	}
+
+	p := r.At(t)
+	local := pl.frame.ToLocal(p.Sub(pl.point.Vec3))
+	hitRecord := NewHit(p, normal, t)
+	hitRecord.SetFaceNormal(r, normal)
+	hitRecord.SetUV(local.X(), local.Y())
+	hitRecord.SetMaterial(pl.mat)
+	return true, hitRecord
+}
+
+func (pl Plane) BoundingBox() AABB { return UniverseAABB() }
+
+// Disk is a flat disk, or an annulus when its inner radius is above zero.
+type Disk struct {
+	center      Point3
+	frame       ONB
+	innerRadius float64
+	radius      float64
+	mat         Material
+	bbox        AABB
+}
+
+func NewDisk(center Point3, normal Vec3, radius float64, material Material) (Disk, error) {
+	return NewAnnulus(center, normal, 0, radius, material)
+}
+
+// NewAnnulus returns the ring between innerRadius and radius around center, facing
+// along normal, which requires 0 <= innerRadius < radius. Hits report the angle around
+// the normal, scaled to [0, 1), as U and the position across the ring from the inner to
+// the outer edge as V.
+func NewAnnulus(center Point3, normal Vec3, innerRadius float64, radius float64, material Material) (Disk, error) {
+	if !(innerRadius >= 0 && innerRadius < radius) {
+		return Disk{}, fmt.Errorf("annulus: radii %v and %v must satisfy 0 <= inner < outer", innerRadius, radius)
+	}
+	return newAnnulus(center, normal, innerRadius, radius, material), nil
+}
+
+// newAnnulus is NewAnnulus for radii that are known to be valid.
+func newAnnulus(center Point3, normal Vec3, innerRadius float64, radius float64, material Material) Disk {
+	frame := NewONB(normal)
+
+	// The disk reaches radius*sqrt(1 - n_i^2) from its center along each world axis.
+	n := frame.W()
+	extent := New(
+		radius*math.Sqrt(math.Max(0, 1-n.x*n.x)),
+		radius*math.Sqrt(math.Max(0, 1-n.y*n.y)),
+		radius*math.Sqrt(math.Max(0, 1-n.z*n.z)))
+	bbox := NewAABBFromPoints(Point3{center.Sub(extent)}, Point3{center.Add(extent)})
+
+	return Disk{center, frame, innerRadius, radius, material, bbox}
+}
+
+func (dk Disk) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	normal := dk.frame.W()
+	denom := Dot(normal, r.Direction())
+	if math.Abs(denom) < 1e-8 {
+		return false, Hit{}
+	}
+	t := Dot(normal, dk.center.Sub(r.Origin().Vec3)) / denom
+	if !rayT.Surrounds(t) {
+		return false, Hit{}
+	}
+
+	p := r.At(t)
+	local := dk.frame.ToLocal(p.Sub(dk.center.Vec3))
+	dist2 := local.X()*local.X() + local.Y()*local.Y()
+	if dist2 > dk.radius*dk.radius || dist2 < dk.innerRadius*dk.innerRadius {
+		return false, Hit{}
+	}
+
+	phi := math.Atan2(local.Y(), local.X())
+	if phi < 0 {
+		phi += 2 * math.Pi
+	}
+	hitRecord := NewHit(p, normal, t)
+	hitRecord.SetFaceNormal(r, normal)
+	hitRecord.SetUV(phi/(2*math.Pi), (math.Sqrt(dist2)-dk.innerRadius)/(dk.radius-dk.innerRadius))
+	hitRecord.SetMaterial(dk.mat)
+	return true, hitRecord
+}
+
+func (dk Disk) BoundingBox() AABB { return dk.bbox }
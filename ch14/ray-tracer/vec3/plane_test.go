@@ -0,0 +1,48 @@
+package vec3
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewAnnulusRadii(t *testing.T) {
+	mat := NewLambertian(NewColor(0.5, 0.5, 0.5))
+	tests := []struct {
+		inner, outer float64
+		ok           bool
+	}{
+		{0, 1, true},
+		{0.5, 1, true},
+		{1, 1, false},
+		{2, 1, false},
+		{-0.5, 1, false},
+		{0, 0, false},
+		{0, math.NaN(), false},
+	}
+	for _, tt := range tests {
+		_, err := NewAnnulus(NewPoint3(0, 0, 0), New(0, 0, 1), tt.inner, tt.outer, mat)
+		if (err == nil) != tt.ok {
+			t.Errorf("NewAnnulus with radii %v and %v: error %v", tt.inner, tt.outer, err)
+		}
+	}
+	if _, err := NewDisk(NewPoint3(0, 0, 0), New(0, 0, 1), 0, mat); err == nil {
+		t.Error("NewDisk accepted a zero radius")
+	}
+}
+
+func TestAnnulusUV(t *testing.T) {
+	annulus, err := NewAnnulus(NewPoint3(0, 0, 0), New(0, 0, 1), 1, 3, NewLambertian(NewColor(0.5, 0.5, 0.5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hit, rec := annulus.Hit(NewRay(NewPoint3(2, 0, 1), New(0, 0, -1)), NewInterval(0.001, math.Inf(1)), nil)
+	if !hit {
+		t.Fatal("no hit half way across the ring")
+	}
+	if v := rec.V(); math.Abs(v-0.5) > 1e-12 {
+		t.Errorf("v = %v half way across the ring, want 0.5", v)
+	}
+	if hit, _ := annulus.Hit(NewRay(NewPoint3(0.5, 0, 1), New(0, 0, -1)), NewInterval(0.001, math.Inf(1)), nil); hit {
+		t.Error("hit inside the hole")
+	}
+}
@@ -0,0 +1,179 @@
+package vec3
+
+import (
+	"math"
+	"sort"
+)
+
+// solvePolynomial returns the real roots of the polynomial whose coefficient of x^i is
+// coeffs[i], in ascending order. Roots are isolated between the extrema found from the
+// derivative, so that each lies in an interval where the polynomial is monotone, and
+// are then refined by Newton's method safeguarded with bisection. Unlike the closed
+// forms for cubics and quartics this does not lose roots to cancellation. A root of
+// even multiplicity, as for a ray grazing a surface, has no sign change to bracket; it
+// is an extremum where the polynomial vanishes, and is found as one.
+func solvePolynomial(coeffs []float64) []float64 {
+	// Drop vanishing leading terms.
+	n := len(coeffs) - 1
+	for n > 0 && coeffs[n] == 0 {
+		n--
+	}
+	coeffs = coeffs[:n+1]
+
+	switch n {
+	case 0:
+		return nil
+	case 1:
+		return []float64{-coeffs[0] / coeffs[1]}
+	case 2:
+		return solveQuadratic(coeffs[2], coeffs[1], coeffs[0])
+	}
+
+	// The extrema of the polynomial are the roots of its derivative.
+	derivative := make([]float64, n)
+	for i := 1; i <= n; i++ {
+		derivative[i-1] = float64(i) * coeffs[i]
+	}
+	extrema := solvePolynomial(derivative)
+
+	// Every root lies within the Cauchy bound.
+	bound := 0.0
+	for _, c := range coeffs[:n] {
+		bound = math.Max(bound, math.Abs(c/coeffs[n]))
+	}
+	bound++
+
+	lo := -bound
+	if len(extrema) > 0 {
+		lo = math.Min(lo, extrema[0]-1)
+	}
+	var roots []float64
+	for i := 0; i <= len(extrema); i++ {
+		hi := bound
+		if i < len(extrema) {
+			hi = extrema[i]
+		} else if len(extrema) > 0 {
+			hi = math.Max(hi, extrema[len(extrema)-1]+1)
+		}
+		if root, ok := bracketRoot(coeffs, lo, hi); ok {
+			roots = append(roots, root)
+		}
+		lo = hi
+	}
+
+	// Take extrema where the polynomial is zero, up to rounding, as roots too.
+	for _, x := range extrema {
+		p, _ := evalPolynomial(coeffs, x)
+		if math.Abs(p) <= rootTolerance*polynomialScale(coeffs, x) {
+			roots = append(roots, x)
+		}
+	}
+	sort.Float64s(roots)
+
+	// A multiple root is only resolved to about the square root of the precision, and
+	// rounding may split it into a cluster of roots, or find it both as an extremum and
+	// by bracketing. Keep one root from each cluster, the one where the polynomial is
+	// closest to zero.
+	merged := roots[:0]
+	for _, x := range roots {
+		last := len(merged) - 1
+		if last < 0 || x-merged[last] > rootResolution*math.Max(1, math.Abs(x)) {
+			merged = append(merged, x)
+			continue
+		}
+		p, _ := evalPolynomial(coeffs, x)
+		pLast, _ := evalPolynomial(coeffs, merged[last])
+		if math.Abs(p) < math.Abs(pLast) {
+			merged[last] = x
+		}
+	}
+	return merged
+}
+
+// rootTolerance is how close to zero, relative to the size of its terms, a polynomial
+// must come at an extremum for the extremum to count as a root.
+const rootTolerance = 1e-12
+
+// rootResolution is the relative distance below which two roots are taken as one.
+const rootResolution = 1e-7
+
+// polynomialScale returns the sum of the magnitudes of the terms of the polynomial at
+// x, which bounds the rounding error of evaluating it there.
+func polynomialScale(coeffs []float64, x float64) float64 {
+	scale := 0.0
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		scale = scale*math.Abs(x) + math.Abs(coeffs[i])
+	}
+	return scale
+}
+
+// solveQuadratic returns the real roots of a*x^2 + b*x + c in ascending order.
+func solveQuadratic(a float64, b float64, c float64) []float64 {
+	discriminant := b*b - 4*a*c
+	if discriminant < 0 {
+		return nil
+	}
+
+	// Avoid the cancellation of the textbook formula.
+	k := -0.5 * (b + math.Copysign(math.Sqrt(discriminant), b))
+	if k == 0 {
+		return []float64{0, 0}
+	}
+	x0, x1 := k/a, c/k
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	return []float64{x0, x1}
+}
+
+// evalPolynomial returns the value of the polynomial and of its derivative at x.
+func evalPolynomial(coeffs []float64, x float64) (float64, float64) {
+	p, dp := 0.0, 0.0
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		dp = dp*x + p
+		p = p*x + coeffs[i]
+	}
+	return p, dp
+}
+
+// bracketRoot finds the root of the polynomial between lo and hi, where it is monotone,
+// reporting false if the polynomial keeps its sign over the interval.
+func bracketRoot(coeffs []float64, lo float64, hi float64) (float64, bool) {
+	pLo, _ := evalPolynomial(coeffs, lo)
+	pHi, _ := evalPolynomial(coeffs, hi)
+	if pLo == 0 {
+		return lo, true
+	}
+	if pHi == 0 {
+		return hi, true
+	}
+	if (pLo < 0) == (pHi < 0) {
+		return 0, false
+	}
+
+	x := 0.5 * (lo + hi)
+	for i := 0; i < 100; i++ {
+		p, dp := evalPolynomial(coeffs, x)
+		if p == 0 {
+			return x, true
+		}
+
+		// Shrink the bracket around the sign change.
+		if (p < 0) == (pLo < 0) {
+			lo = x
+		} else {
+			hi = x
+		}
+
+		// Take the Newton step unless it leaves the bracket, and bisect otherwise.
+		next := x - p/dp
+		if !(next > lo && next < hi) {
+			next = 0.5 * (lo + hi)
+		}
+		if next == x || hi-lo <= 1e-15*math.Max(1, math.Abs(x)) {
+			return next, true
+		}
+		x = next
+	}
+	return x, true
+}
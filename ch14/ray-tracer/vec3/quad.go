@@ -0,0 +1,91 @@
+package vec3
+
+import "math"
+
+// Quad is a parallelogram with corner q and edges u and v. Its front face is the one
+// that u and v wind counter-clockwise around.
+type Quad struct {
+	q      Point3
+	u, v   Vec3
+	w      Vec3 // Scaled plane normal, for the planar coordinates of a hit
+	mat    Material
+	bbox   AABB
+	normal Vec3
+	d      float64 // Plane constant, Dot(normal, p) == d for points p on the plane
+}
+
+func NewQuad(q Point3, u Vec3, v Vec3, material Material) Quad {
+	n := Cross(u, v)
+	normal := UnitVector(n)
+
+	// Compute the bounding box of all four vertices.
+	bboxDiagonal1 := NewAABBFromPoints(q, Point3{q.Add(u).Add(v)})
+	bboxDiagonal2 := NewAABBFromPoints(Point3{q.Add(u)}, Point3{q.Add(v)})
+
+	return Quad{
+		q:      q,
+		u:      u,
+		v:      v,
+		w:      n.Div(Dot(n, n)),
+		mat:    material,
+		bbox:   NewAABBEnclosing(bboxDiagonal1, bboxDiagonal2),
+		normal: normal,
+		d:      Dot(normal, q.Vec3),
+	}
+}
+
+func (qd Quad) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	denom := Dot(qd.normal, r.Direction())
+
+	// No hit if the ray is parallel to the plane.
+	if math.Abs(denom) < 1e-8 {
+		return false, Hit{}
+	}
+
+	// Return false if the hit point parameter t is outside the ray interval.
+	t := (qd.d - Dot(qd.normal, r.Origin().Vec3)) / denom
+	if !rayT.Surrounds(t) {
+		return false, Hit{}
+	}
+
+	// Determine if the hit point lies within the planar shape using its plane coordinates.
+	intersection := r.At(t)
+	planarHitptVector := intersection.Sub(qd.q.Vec3)
+	alpha := Dot(qd.w, Cross(planarHitptVector, qd.v))
+	beta := Dot(qd.w, Cross(qd.u, planarHitptVector))
+
+	unit := NewInterval(0, 1)
+	if !unit.Contains(alpha) || !unit.Contains(beta) {
+		return false, Hit{}
+	}
+
+	hitRecord := NewHit(intersection, qd.normal, t)
+	hitRecord.SetFaceNormal(r, qd.normal)
+	hitRecord.SetUV(alpha, beta)
+	hitRecord.SetMaterial(qd.mat)
+	return true, hitRecord
+}
+
+func (qd Quad) BoundingBox() AABB { return qd.bbox }
+
+// NewBox returns the closed box with opposite corners a and b, as six outward facing quads.
+func NewBox(a Point3, b Point3, material Material) HittableList {
+	sides := HittableList{}
+
+	// Construct the two opposite vertices with the minimum and maximum coordinates.
+	lo := NewPoint3(math.Min(a.X(), b.X()), math.Min(a.Y(), b.Y()), math.Min(a.Z(), b.Z()))
+	hi := NewPoint3(math.Max(a.X(), b.X()), math.Max(a.Y(), b.Y()), math.Max(a.Z(), b.Z()))
+
+	dx := New(hi.X()-lo.X(), 0, 0)
+	dy := New(0, hi.Y()-lo.Y(), 0)
+	dz := New(0, 0, hi.Z()-lo.Z())
+
+	sides.Add(NewQuad(NewPoint3(lo.X(), lo.Y(), hi.Z()), dx, dy, material))       // front
+	sides.Add(NewQuad(NewPoint3(hi.X(), lo.Y(), hi.Z()), dz.Inv(), dy, material)) // right
+	sides.Add(NewQuad(NewPoint3(hi.X(), lo.Y(), lo.Z()), dx.Inv(), dy, material)) // back
+	sides.Add(NewQuad(NewPoint3(lo.X(), lo.Y(), lo.Z()), dz, dy, material))       // left
+	sides.Add(NewQuad(NewPoint3(lo.X(), hi.Y(), hi.Z()), dx, dz.Inv(), material)) // top
+	sides.Add(NewQuad(NewPoint3(lo.X(), lo.Y(), lo.Z()), dx, dz, material))       // bottom
+
+	return sides
+}
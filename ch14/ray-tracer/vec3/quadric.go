@@ -0,0 +1,248 @@
+package vec3
+
+import (
+	"fmt"
+	"math"
+)
+
+// quadric is the surface a*x^2 + b*y^2 + c*z^2 + d*z + e = 0 in a local frame whose z
+// axis is the axis of the shape, clipped to zMin <= z <= zMax and optionally closed by
+// flat caps. The surface is oriented so that the quadric is negative inside.
+type quadric struct {
+	origin     Point3
+	frame      ONB
+	a, b, c    float64
+	d, e       float64
+	zMin, zMax float64
+	caps       []Disk
+	mat        Material
+	bbox       AABB
+}
+
+func newQuadric(origin Point3, frame ONB, coeffs [5]float64, zMin float64, zMax float64, extent Vec3, material Material) quadric {
+	q := quadric{
+		origin: origin,
+		frame:  frame,
+		a:      coeffs[0],
+		b:      coeffs[1],
+		c:      coeffs[2],
+		d:      coeffs[3],
+		e:      coeffs[4],
+		zMin:   zMin,
+		zMax:   zMax,
+		mat:    material,
+	}
+
+	q.bbox = frameBox(origin, frame, New(-extent.x, -extent.y, zMin), New(extent.x, extent.y, zMax))
+	return q
+}
+
+// frameBox returns the world space bounding box of the box from lo to hi in the local
+// frame at origin.
+func frameBox(origin Point3, frame ONB, lo Vec3, hi Vec3) AABB {
+	bbox := EmptyAABB()
+	for i := 0; i < 8; i++ {
+		x, y, z := lo.x, lo.y, lo.z
+		if i&1 != 0 {
+			x = hi.x
+		}
+		if i&2 != 0 {
+			y = hi.y
+		}
+		if i&4 != 0 {
+			z = hi.z
+		}
+		corner := Point3{origin.Add(frame.Local(x, y, z))}
+		bbox = NewAABBEnclosing(bbox, NewAABBFromPoints(corner, corner))
+	}
+	return bbox
+}
+
+// addCap closes the quadric with a disk of the given radius at height z on its axis,
+// facing along the axis when up is set and against it otherwise.
+func (q *quadric) addCap(z float64, radius float64, up bool) {
+	normal := q.frame.W()
+	if !up {
+		normal = normal.Inv()
+	}
+	center := Point3{q.origin.Add(q.frame.W().Mul(z))}
+	q.caps = append(q.caps, newAnnulus(center, normal, 0, radius, q.mat))
+}
+
+func (q quadric) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	hitAnything, rec := q.hitSurface(r, rayT)
+	for _, disk := range q.caps {
+		if hitAnything {
+			rayT = NewInterval(rayT.Min(), rec.T())
+		}
+		if hitCap, capRec := disk.Hit(r, rayT, rng); hitCap {
+			hitAnything, rec = true, capRec
+		}
+	}
+	return hitAnything, rec
+}
+
+func (q quadric) hitSurface(r Ray, rayT Interval) (bool, Hit) {
+	// Solve in the local frame, where the ray keeps its parameterization.
+	o := q.frame.ToLocal(r.Origin().Sub(q.origin.Vec3))
+	dir := q.frame.ToLocal(r.Direction())
+
+	qa := q.a*dir.x*dir.x + q.b*dir.y*dir.y + q.c*dir.z*dir.z
+	qb := 2*(q.a*o.x*dir.x+q.b*o.y*dir.y+q.c*o.z*dir.z) + q.d*dir.z
+	qc := q.a*o.x*o.x + q.b*o.y*o.y + q.c*o.z*o.z + q.d*o.z + q.e
+
+	// The quadratic term can cancel, to within rounding of its terms, for rays along the
+	// axis of a paraboloid or an asymptote of a cone or hyperboloid.
+	var roots []float64
+	scale := (math.Abs(q.a) + math.Abs(q.b) + math.Abs(q.c)) * dir.LengthSquared()
+	if math.Abs(qa) <= 1e-12*scale {
+		// The equation degenerates to a line, as for rays along a paraboloid axis.
+		if qb == 0 {
+			return false, Hit{}
+		}
+		roots = []float64{-qc / qb}
+	} else {
+		roots = solveQuadratic(qa, qb, qc)
+	}
+
+	// Take the nearest root in the ray interval that lies within the clipped height.
+	for _, t := range roots {
+		if !rayT.Surrounds(t) {
+			continue
+		}
+		z := o.z + t*dir.z
+		if z < q.zMin || z > q.zMax {
+			continue
+		}
+		x, y := o.x+t*dir.x, o.y+t*dir.y
+
+		// The gradient of the quadric points out of the shape.
+		outwardNormal := UnitVector(q.frame.Local(2*q.a*x, 2*q.b*y, 2*q.c*z+q.d))
+		phi := math.Atan2(y, x)
+		if phi < 0 {
+			phi += 2 * math.Pi
+		}
+
+		hitRecord := NewHit(r.At(t), outwardNormal, t)
+		hitRecord.SetFaceNormal(r, outwardNormal)
+		hitRecord.SetUV(phi/(2*math.Pi), (z-q.zMin)/(q.zMax-q.zMin))
+		hitRecord.SetMaterial(q.mat)
+		return true, hitRecord
+	}
+	return false, Hit{}
+}
+
+func (q quadric) BoundingBox() AABB { return q.bbox }
+
+// positive reports whether all of values are above zero, and none is NaN.
+func positive(values ...float64) bool {
+	for _, v := range values {
+		if !(v > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// The shapes below are all built around an axis vector that runs from a base point to
+// the far end of the shape. Hits on their curved surfaces report the angle around the
+// axis, scaled to [0, 1), as U and the height along the axis, scaled to [0, 1], as V.
+
+// Cylinder is a circular cylinder, optionally closed by caps at both ends.
+type Cylinder struct{ quadric }
+
+func NewCylinder(base Point3, axis Vec3, radius float64, capped bool, material Material) (Cylinder, error) {
+	height := axis.Length()
+	if !positive(radius, height) {
+		return Cylinder{}, fmt.Errorf("cylinder: radius %v and height %v must be positive", radius, height)
+	}
+	q := newQuadric(base, NewONB(axis),
+		[5]float64{1, 1, 0, 0, -radius * radius},
+		0, height, New(radius, radius, 0), material)
+	if capped {
+		q.addCap(0, radius, false)
+		q.addCap(height, radius, true)
+	}
+	return Cylinder{q}, nil
+}
+
+// Cone is a circular cone with its base around base and its apex at base + axis,
+// optionally closed by a cap over the base.
+type Cone struct{ quadric }
+
+func NewCone(base Point3, axis Vec3, radius float64, capped bool, material Material) (Cone, error) {
+	height := axis.Length()
+	if !positive(radius, height) {
+		return Cone{}, fmt.Errorf("cone: radius %v and height %v must be positive", radius, height)
+	}
+
+	// x^2 + y^2 = (k*(height - z))^2, with the radius shrinking linearly to the apex.
+	k2 := (radius / height) * (radius / height)
+	q := newQuadric(base, NewONB(axis),
+		[5]float64{1, 1, -k2, 2 * k2 * height, -k2 * height * height},
+		0, height, New(radius, radius, 0), material)
+	if capped {
+		q.addCap(0, radius, false)
+	}
+	return Cone{q}, nil
+}
+
+// Paraboloid is a bowl with its vertex at vertex that opens to the given radius at
+// vertex + axis, optionally closed by a cap over the opening.
+type Paraboloid struct{ quadric }
+
+func NewParaboloid(vertex Point3, axis Vec3, radius float64, capped bool, material Material) (Paraboloid, error) {
+	height := axis.Length()
+	if !positive(radius, height) {
+		return Paraboloid{}, fmt.Errorf("paraboloid: radius %v and height %v must be positive", radius, height)
+	}
+
+	// x^2 + y^2 = radius^2 * z / height
+	q := newQuadric(vertex, NewONB(axis),
+		[5]float64{1, 1, 0, -radius * radius / height, 0},
+		0, height, New(radius, radius, 0), material)
+	if capped {
+		q.addCap(height, radius, true)
+	}
+	return Paraboloid{q}, nil
+}
+
+// Hyperboloid is a hyperboloid of one sheet, narrowing from endRadius at base and
+// base + axis to waistRadius half way between them. Either radius may be zero, making a
+// double cone or a spheroid, but not both.
+type Hyperboloid struct{ quadric }
+
+func NewHyperboloid(base Point3, axis Vec3, waistRadius float64, endRadius float64, material Material) (Hyperboloid, error) {
+	height := axis.Length()
+	if !positive(height) || !(waistRadius >= 0) || !(endRadius >= 0) || waistRadius+endRadius == 0 {
+		return Hyperboloid{}, fmt.Errorf("hyperboloid: invalid radii %v and %v or height %v", waistRadius, endRadius, height)
+	}
+	half := height / 2
+
+	// x^2 + y^2 = waistRadius^2 + s*(z - half)^2, with s fixing the radius at the ends.
+	s := (endRadius*endRadius - waistRadius*waistRadius) / (half * half)
+	extent := math.Max(waistRadius, endRadius)
+	q := newQuadric(base, NewONB(axis),
+		[5]float64{1, 1, -s, 2 * s * half, -s*half*half - waistRadius*waistRadius},
+		0, height, New(extent, extent, 0), material)
+	return Hyperboloid{q}, nil
+}
+
+// Ellipsoid is an ellipsoid around center. Unlike the shapes above it is not symmetric
+// around its axis, so it is placed with a whole local frame, and its V coordinate runs
+// along the W axis of the frame.
+type Ellipsoid struct{ quadric }
+
+// NewEllipsoid returns the ellipsoid around center whose semi-axes run along the U, V
+// and W axes of frame, with lengths radii.X(), radii.Y() and radii.Z(). NewONBFromAxes
+// builds a frame with a chosen orientation.
+func NewEllipsoid(center Point3, frame ONB, radii Vec3, material Material) (Ellipsoid, error) {
+	rx, ry, rz := radii.x, radii.y, radii.z
+	if !positive(rx, ry, rz) {
+		return Ellipsoid{}, fmt.Errorf("ellipsoid: radii %v, %v and %v must be positive", rx, ry, rz)
+	}
+	q := newQuadric(center, frame,
+		[5]float64{1 / (rx * rx), 1 / (ry * ry), 1 / (rz * rz), 0, -1},
+		-rz, rz, New(rx, ry, 0), material)
+	return Ellipsoid{q}, nil
+}
@@ -0,0 +1,79 @@
+package vec3
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuadricDirectionScale(t *testing.T) {
+	mat := NewLambertian(NewColor(0.5, 0.5, 0.5))
+	paraboloid, err := NewParaboloid(NewPoint3(0, 0, 0), New(0, 0, 100), 100, false, mat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cone, err := NewCone(NewPoint3(0, 0, 0), New(0, 0, 1), 1, false, mat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sphere, err := NewEllipsoid(NewPoint3(0, 0, 0), NewONB(New(0, 0, 1)), New(1, 1, 1), mat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planet, err := NewEllipsoid(NewPoint3(0, 0, 0), NewONB(New(0, 0, 1)), New(1e7, 1e7, 1e7), mat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		object Hittable
+		origin Point3
+		dir    Vec3
+		dist   float64 // Distance to the hit along the ray
+	}{
+		// Small coefficients, and tiny directions once scaled, make the quadratic term
+		// small without it vanishing.
+		{"unit sphere", sphere, NewPoint3(0, 0, 5), New(0, 0, -1), 4},
+		{"large sphere", planet, NewPoint3(0, 0, 2e7), New(0, 0, -1), 1e7},
+		// Parallel to the axis, where the quadratic term vanishes.
+		{"paraboloid along axis", paraboloid, NewPoint3(10, 0, 150), New(0, 0, -1), 149},
+		// Nearly parallel, where it nearly does.
+		{"paraboloid near axis", paraboloid, NewPoint3(10, 0, 150), New(1e-9, 0, -1), 149},
+		// Parallel to the side of the cone.
+		{"cone side", cone, NewPoint3(-1, 0, 0.5), New(1, 0, -1), 0.25 * math.Sqrt2},
+	}
+	for _, tt := range tests {
+		for _, scale := range []float64{1e-8, 1, 1e6} {
+			r := NewRay(tt.origin, tt.dir.Mul(scale))
+			hit, rec := tt.object.Hit(r, NewInterval(1e-12, math.Inf(1)), nil)
+			if !hit {
+				t.Errorf("%s, direction scaled by %v: no hit", tt.name, scale)
+				continue
+			}
+			dist := rec.T() * r.Direction().Length()
+			if math.Abs(dist-tt.dist) > 1e-6 {
+				t.Errorf("%s, direction scaled by %v: hit at distance %v, want %v", tt.name, scale, dist, tt.dist)
+			}
+		}
+	}
+}
+
+func TestQuadricConstructorsRejectDegenerateShapes(t *testing.T) {
+	mat := NewLambertian(NewColor(0.5, 0.5, 0.5))
+	o := NewPoint3(0, 0, 0)
+	up := New(0, 1, 0)
+	errs := map[string]error{}
+	_, errs["cylinder with zero radius"] = NewCylinder(o, up, 0, true, mat)
+	_, errs["cylinder with zero height"] = NewCylinder(o, New(0, 0, 0), 1, true, mat)
+	_, errs["cone with negative radius"] = NewCone(o, up, -1, true, mat)
+	_, errs["paraboloid with NaN radius"] = NewParaboloid(o, up, math.NaN(), true, mat)
+	_, errs["hyperboloid with zero radii"] = NewHyperboloid(o, up, 0, 0, mat)
+	_, errs["hyperboloid with zero height"] = NewHyperboloid(o, New(0, 0, 0), 1, 2, mat)
+	_, errs["ellipsoid with zero radius"] = NewEllipsoid(o, NewONB(up), New(1, 0, 1), mat)
+	for name, err := range errs {
+		if err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package vec3
 type Ray struct {
 	origin    Point3
 	direction Vec3
+	tm        float64 // Time at which the ray samples the scene, for motion blur
 }
 
 func (r Ray) Origin() Point3 {
@@ -13,8 +14,17 @@ func (r Ray) Direction() Vec3 {
 	return r.direction
 }
 
+func (r Ray) Time() float64 {
+	return r.tm
+}
+
 func NewRay(o Point3, d Vec3) Ray {
-	r := Ray{o, d}
+	r := Ray{o, d, 0}
+	return r
+}
+
+func NewRayAtTime(o Point3, d Vec3, tm float64) Ray {
+	r := Ray{o, d, tm}
 	return r
 }
 
@@ -0,0 +1,59 @@
+package vec3
+
+// RNG is a source of uniformly distributed random numbers in [0, 1). All sampling and
+// scattering draws from an RNG passed in by the caller rather than from a global source.
+type RNG interface {
+	Float64() float64
+}
+
+// PCG is a PCG32 (XSH RR) generator. It is small enough to be reseeded for every
+// camera sample, which makes each sample's random stream independent of scheduling.
+type PCG struct {
+	state uint64
+	inc   uint64
+}
+
+const pcgMultiplier = 6364136223846793005
+
+func NewPCG(seed uint64, stream uint64) *PCG {
+	p := &PCG{}
+	p.Seed(seed, stream)
+	return p
+}
+
+func (p *PCG) Seed(seed uint64, stream uint64) {
+	p.state = 0
+	p.inc = stream<<1 | 1
+	p.Uint32()
+	p.state += seed
+	p.Uint32()
+}
+
+// SeedSample seeds the generator with the stream for one sample of one pixel, so the
+// same (seed, pixel, sample) always yields the same numbers.
+func (p *PCG) SeedSample(seed uint64, pixel int, sample int) {
+	p.Seed(mix64(seed^mix64(uint64(sample))), uint64(pixel))
+}
+
+func (p *PCG) Uint32() uint32 {
+	old := p.state
+	p.state = old*pcgMultiplier + p.inc
+	xorShifted := uint32(((old >> 18) ^ old) >> 27)
+	rot := uint32(old >> 59)
+	return xorShifted>>rot | xorShifted<<((-rot)&31)
+}
+
+func (p *PCG) Float64() float64 {
+	// Combine two outputs into the 53 bits of precision a float64 can hold.
+	hi := uint64(p.Uint32()) >> 5
+	lo := uint64(p.Uint32()) >> 6
+	return float64(hi<<26|lo) / (1 << 53)
+}
+
+func mix64(x uint64) uint64 {
+	// SplitMix64 finalizer, used to decorrelate nearby seeds.
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -5,17 +5,51 @@ import (
 )
 
 type Sphere struct {
-	center Point3
-	radius float64
-	mat    Material
+	center    Point3
+	radius    float64
+	mat       Material
+	bbox      AABB
+	centerVec Vec3 // Distance the center moves per unit of time
+	isMoving  bool
 }
 
 func NewSphere(center Point3, radius float64, material Material) Sphere {
-	return Sphere{center, radius, material}
+	rvec := New(radius, radius, radius)
+	bbox := NewAABBFromPoints(Point3{center.Sub(rvec)}, Point3{center.Add(rvec)})
+	return Sphere{center: center, radius: radius, mat: material, bbox: bbox}
 }
 
-func (s Sphere) Hit(r Ray, rayT Interval) (bool, Hit) {
-	oc := r.Origin().Sub(s.center.Vec3)
+// NewMovingSphere returns a sphere that moves linearly from center1 at time 0 to
+// center2 at time 1, and rests at those ends before and after. Its bounding box
+// encloses the sphere at both ends of the motion.
+func NewMovingSphere(center1 Point3, center2 Point3, radius float64, material Material) Sphere {
+	rvec := New(radius, radius, radius)
+	box1 := NewAABBFromPoints(Point3{center1.Sub(rvec)}, Point3{center1.Add(rvec)})
+	box2 := NewAABBFromPoints(Point3{center2.Sub(rvec)}, Point3{center2.Add(rvec)})
+	return Sphere{
+		center:    center1,
+		radius:    radius,
+		mat:       material,
+		bbox:      NewAABBEnclosing(box1, box2),
+		centerVec: center2.Sub(center1.Vec3),
+		isMoving:  true,
+	}
+}
+
+func (s Sphere) centerAt(time float64) Point3 {
+	// Linearly interpolate from center1 to center2 according to time, where t=0 yields
+	// center1, and t=1 yields center2. Times outside the motion are clamped, so that the
+	// sphere stays within its bounding box for any shutter interval.
+	if !s.isMoving {
+		return s.center
+	}
+	time = math.Max(0, math.Min(1, time))
+	return Point3{s.center.Add(s.centerVec.Mul(time))}
+}
+
+func (s Sphere) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	center := s.centerAt(r.Time())
+	oc := r.Origin().Sub(center.Vec3)
 	a := r.Direction().LengthSquared()
 	halfB := Dot(oc, r.Direction())
 	c := oc.LengthSquared() - s.radius*s.radius
@@ -37,10 +71,26 @@ func (s Sphere) Hit(r Ray, rayT Interval) (bool, Hit) {
 
 	hitRecT := root
 	hitRecP := r.At(hitRecT)
-	hitRecNormal := (hitRecP.Sub(s.center.Vec3)).Div(s.radius)
+	hitRecNormal := (hitRecP.Sub(center.Vec3)).Div(s.radius)
 	hitRecord := NewHit(hitRecP, hitRecNormal, hitRecT)
 	hitRecord.SetFaceNormal(r, hitRecNormal)
+	hitRecord.SetUV(sphereUV(hitRecNormal))
 	hitRecord.SetMaterial(s.mat)
 
 	return true, hitRecord
 }
+
+func (s Sphere) BoundingBox() AABB { return s.bbox }
+
+func sphereUV(p Vec3) (float64, float64) {
+	// p: a given point on the sphere of radius one, centered at the origin.
+	// u: returned value [0,1] of angle around the Y axis from X=-1.
+	// v: returned value [0,1] of angle from Y=-1 to Y=+1.
+	//     <1 0 0> yields <0.50 0.50>       <-1  0  0> yields <0.00 0.50>
+	//     <0 1 0> yields <0.50 1.00>       < 0 -1  0> yields <0.50 0.00>
+	//     <0 0 1> yields <0.25 0.50>       < 0  0 -1> yields <0.75 0.50>
+
+	theta := math.Acos(math.Max(-1, math.Min(1, -p.y)))
+	phi := math.Atan2(-p.z, p.x) + math.Pi
+	return phi / (2 * math.Pi), theta / math.Pi
+}
@@ -0,0 +1,51 @@
+package vec3
+
+import "math"
+
+// Texture gives a color for the surface coordinates u, v of a hit at point p. Textures
+// that hold large data, such as images, are best used through pointers, so that copies
+// of the materials holding them stay small.
+type Texture interface {
+	Value(u float64, v float64, p Point3) Color
+}
+
+// SolidColor is a texture of a single color.
+type SolidColor struct {
+	albedo Color
+}
+
+func NewSolidColor(albedo Color) SolidColor {
+	return SolidColor{albedo}
+}
+
+func (s SolidColor) Value(u float64, v float64, p Point3) Color {
+	return s.albedo
+}
+
+// CheckerTexture alternates between two textures in a 3D checkerboard of cubes of the
+// given size, so that it can be used on any surface without UVs.
+type CheckerTexture struct {
+	invScale float64
+	even     Texture
+	odd      Texture
+}
+
+func NewCheckerTexture(scale float64, even Texture, odd Texture) CheckerTexture {
+	return CheckerTexture{1 / scale, even, odd}
+}
+
+func NewCheckerColors(scale float64, c1 Color, c2 Color) CheckerTexture {
+	return NewCheckerTexture(scale, NewSolidColor(c1), NewSolidColor(c2))
+}
+
+func (c CheckerTexture) Value(u float64, v float64, p Point3) Color {
+	xInteger := int(math.Floor(c.invScale * p.x))
+	yInteger := int(math.Floor(c.invScale * p.y))
+	zInteger := int(math.Floor(c.invScale * p.z))
+
+	isEven := (xInteger+yInteger+zInteger)%2 == 0
+	if isEven {
+		return c.even.Value(u, v, p)
+	}
+	return c.odd.Value(u, v, p)
+}
@@ -0,0 +1,145 @@
+package vec3
+
+import (
+	"fmt"
+	"math"
+)
+
+// ToneMapper maps linear scene radiance to display-encoded components in [0, 1].
+type ToneMapper interface {
+	Map(c Color) Color
+}
+
+// GammaToneMapper scales the radiance by the exposure, in EV, and applies the gamma 2
+// transform used since the first chapters: a square root. It is the default.
+type GammaToneMapper struct {
+	Exposure float64
+}
+
+func (t GammaToneMapper) Map(c Color) Color {
+	c = expose(c, t.Exposure)
+	return NewColor(linearToGamma(math.Max(c.x, 0)), linearToGamma(math.Max(c.y, 0)), linearToGamma(math.Max(c.z, 0)))
+}
+
+// LinearToneMapper passes values through unchanged, apart from clamping them to [0, 1].
+// It suits buffers of data, such as normals or depth, rather than radiance.
+type LinearToneMapper struct{}
+
+func (LinearToneMapper) Map(c Color) Color {
+	unit := NewInterval(0, 1)
+	return NewColor(unit.clamp(c.x), unit.clamp(c.y), unit.clamp(c.z))
+}
+
+// SRGBToneMapper scales the radiance by the exposure, in EV, and encodes it with the
+// sRGB transfer function. Values above 1 are clipped.
+type SRGBToneMapper struct {
+	Exposure float64
+}
+
+func (t SRGBToneMapper) Map(c Color) Color {
+	c = expose(c, t.Exposure)
+	return NewColor(srgbOETF(c.x), srgbOETF(c.y), srgbOETF(c.z))
+}
+
+// ReinhardToneMapper applies the extended Reinhard operator to the luminance, so that
+// a luminance of White maps to 1. A White of zero or less gives the plain operator.
+type ReinhardToneMapper struct {
+	Exposure float64
+	White    float64
+}
+
+func (t ReinhardToneMapper) Map(c Color) Color {
+	c = expose(c, t.Exposure)
+	l := luminance(c)
+	if l <= 0 {
+		return NewColor(0, 0, 0)
+	}
+	lOut := l / (1 + l)
+	if t.White > 0 {
+		lOut = l * (1 + l/(t.White*t.White)) / (1 + l)
+	}
+	c.Vec3 = c.Mul(lOut / l)
+	return NewColor(srgbOETF(c.x), srgbOETF(c.y), srgbOETF(c.z))
+}
+
+// ACESToneMapper applies Narkowicz's fit of the ACES filmic curve.
+type ACESToneMapper struct {
+	Exposure float64
+}
+
+func (t ACESToneMapper) Map(c Color) Color {
+	c = expose(c, t.Exposure)
+	aces := func(x float64) float64 {
+		x = math.Max(x, 0)
+		return srgbOETF((x * (2.51*x + 0.03)) / (x*(2.43*x+0.59) + 0.14))
+	}
+	return NewColor(aces(c.x), aces(c.y), aces(c.z))
+}
+
+// HableToneMapper applies John Hable's Uncharted 2 filmic curve, normalized so that
+// White maps to 1. A White of zero or less uses the customary 11.2.
+type HableToneMapper struct {
+	Exposure float64
+	White    float64
+}
+
+func (t HableToneMapper) Map(c Color) Color {
+	c = expose(c, t.Exposure)
+	white := t.White
+	if white <= 0 {
+		white = 11.2
+	}
+	// The curve was designed to be fed twice the scene exposure.
+	scale := 1 / hable(white)
+	curve := func(x float64) float64 {
+		return srgbOETF(hable(2*math.Max(x, 0)) * scale)
+	}
+	return NewColor(curve(c.x), curve(c.y), curve(c.z))
+}
+
+// ParseToneMapper returns the tone mapper called name (gamma, srgb, reinhard, aces or
+// hable) with the given exposure in EV and white point. A white point of zero keeps the
+// default of the mapper; only reinhard and hable take one.
+func ParseToneMapper(name string, exposure float64, white float64) (ToneMapper, error) {
+	switch name {
+	case "reinhard":
+		return ReinhardToneMapper{Exposure: exposure, White: white}, nil
+	case "hable":
+		return HableToneMapper{Exposure: exposure, White: white}, nil
+	}
+
+	if white != 0 {
+		return nil, fmt.Errorf("tone mapper %q has no white point", name)
+	}
+	switch name {
+	case "gamma":
+		return GammaToneMapper{Exposure: exposure}, nil
+	case "srgb":
+		return SRGBToneMapper{Exposure: exposure}, nil
+	case "aces":
+		return ACESToneMapper{Exposure: exposure}, nil
+	}
+	return nil, fmt.Errorf("unknown tone mapper %q", name)
+}
+
+func expose(c Color, ev float64) Color {
+	return Color{c.Mul(math.Exp2(ev))}
+}
+
+func luminance(c Color) float64 {
+	// Rec. 709 luminance of a linear color.
+	return 0.2126*c.x + 0.7152*c.y + 0.0722*c.z
+}
+
+func srgbOETF(x float64) float64 {
+	x = NewInterval(0, 1).clamp(x)
+	if x <= 0.0031308 {
+		return 12.92 * x
+	}
+	return 1.055*math.Pow(x, 1/2.4) - 0.055
+}
+
+func hable(x float64) float64 {
+	const a, b, c, d, e, f = 0.15, 0.50, 0.10, 0.20, 0.02, 0.30
+	return (x*(a*x+c*b)+d*e)/(x*(a*x+b)+d*f) - e/f
+}
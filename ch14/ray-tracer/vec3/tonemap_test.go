@@ -0,0 +1,35 @@
+package vec3
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseToneMapper(t *testing.T) {
+	tm, err := ParseToneMapper("reinhard", 0, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Extended Reinhard maps a luminance of White to 1.
+	if got := tm.Map(NewColor(4, 4, 4)); math.Abs(got.X()-1) > 1e-12 {
+		t.Errorf("reinhard with white 4 maps 4 to %v, want 1", got.X())
+	}
+
+	tm, err = ParseToneMapper("gamma", 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two stops up turn 1/16 into 1/4, which the square root maps to 1/2.
+	if got := tm.Map(NewColor(1.0/16, 1.0/16, 1.0/16)); math.Abs(got.X()-0.5) > 1e-12 {
+		t.Errorf("gamma at +2 EV maps 1/16 to %v, want 0.5", got.X())
+	}
+
+	for _, name := range []string{"gamma", "srgb", "aces"} {
+		if _, err := ParseToneMapper(name, 0, 4); err == nil {
+			t.Errorf("%s accepted a white point", name)
+		}
+	}
+	if _, err := ParseToneMapper("filmic", 0, 0); err == nil {
+		t.Error("unknown tone mapper accepted")
+	}
+}
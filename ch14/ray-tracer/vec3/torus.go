@@ -0,0 +1,87 @@
+package vec3
+
+import (
+	"fmt"
+	"math"
+)
+
+// Torus is a ring with its center hole around an axis through center. The tube of
+// radius minorRadius runs around the axis at majorRadius from it.
+type Torus struct {
+	center      Point3
+	frame       ONB
+	majorRadius float64
+	minorRadius float64
+	mat         Material
+	bbox        AABB
+}
+
+// NewTorus returns the torus around center whose hole looks along axis. Hits report the
+// angle around the axis as U and the angle around the tube, starting from its outer
+// equator, as V, both scaled to [0, 1). The tube must be thinner than the ring is wide,
+// 0 < minorRadius < majorRadius, so that the torus keeps its hole; horn and spindle tori
+// are not supported.
+func NewTorus(center Point3, axis Vec3, majorRadius float64, minorRadius float64, material Material) (Torus, error) {
+	if !(minorRadius > 0 && minorRadius < majorRadius) {
+		return Torus{}, fmt.Errorf("torus: radii %v and %v must satisfy 0 < minor < major", majorRadius, minorRadius)
+	}
+	frame := NewONB(axis)
+	extent := majorRadius + minorRadius
+	bbox := frameBox(center, frame, New(-extent, -extent, -minorRadius), New(extent, extent, minorRadius))
+	return Torus{center, frame, majorRadius, minorRadius, material, bbox}, nil
+}
+
+func (tr Torus) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	// Work in the local frame with a unit direction, starting from the point of the ray
+	// closest to the center. This keeps the quartic coefficients small, which matters
+	// for rays that start far away.
+	dir := tr.frame.ToLocal(r.Direction())
+	length := dir.Length()
+	d := dir.Div(length)
+	o := tr.frame.ToLocal(r.Origin().Sub(tr.center.Vec3))
+	shift := -Dot(o, d)
+	o = o.Add(d.Mul(shift))
+
+	// Substitute the ray into (|p|^2 + R^2 - r^2)^2 = 4 R^2 (x^2 + y^2).
+	R2 := tr.majorRadius * tr.majorRadius
+	f := Dot(o, d)
+	g := Dot(o, o) + R2 - tr.minorRadius*tr.minorRadius
+	roots := solvePolynomial([]float64{
+		g*g - 4*R2*(o.x*o.x+o.y*o.y),
+		4*f*g - 8*R2*(o.x*d.x+o.y*d.y),
+		4*f*f + 2*g - 4*R2*(d.x*d.x+d.y*d.y),
+		4 * f,
+		1,
+	})
+
+	for _, s := range roots {
+		t := (shift + s) / length
+		if !rayT.Surrounds(t) {
+			continue
+		}
+
+		// The normal points away from the nearest point on the center line of the tube,
+		// which is well defined as the tube never reaches the axis.
+		p := o.Add(d.Mul(s))
+		rho := math.Hypot(p.x, p.y)
+		outwardNormal := UnitVector(tr.frame.Local(p.x-tr.majorRadius*p.x/rho, p.y-tr.majorRadius*p.y/rho, p.z))
+
+		phi := math.Atan2(p.y, p.x)
+		if phi < 0 {
+			phi += 2 * math.Pi
+		}
+		theta := math.Atan2(p.z, rho-tr.majorRadius)
+		if theta < 0 {
+			theta += 2 * math.Pi
+		}
+
+		hitRecord := NewHit(r.At(t), outwardNormal, t)
+		hitRecord.SetFaceNormal(r, outwardNormal)
+		hitRecord.SetUV(phi/(2*math.Pi), theta/(2*math.Pi))
+		hitRecord.SetMaterial(tr.mat)
+		return true, hitRecord
+	}
+	return false, Hit{}
+}
+
+func (tr Torus) BoundingBox() AABB { return tr.bbox }
@@ -0,0 +1,102 @@
+package vec3
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSolvePolynomial(t *testing.T) {
+	tests := []struct {
+		name   string
+		coeffs []float64 // coeffs[i] is the coefficient of x^i
+		want   []float64
+	}{
+		{"linear", []float64{-3, 2}, []float64{1.5}},
+		{"quadratic", []float64{2, -3, 1}, []float64{1, 2}},
+		{"quadratic without roots", []float64{1, 0, 1}, nil},
+		{"cubic", []float64{-6, 11, -6, 1}, []float64{1, 2, 3}},
+		{"quartic", []float64{24, -50, 35, -10, 1}, []float64{1, 2, 3, 4}},
+		{"quartic with a double root", []float64{6, -17, 17, -7, 1}, []float64{1, 2, 3}},
+		{"quartic with two double roots", []float64{4, -12, 13, -6, 1}, []float64{1, 2}},
+		{"quartic without real roots", []float64{4, 0, 5, 0, 1}, nil},
+		{"vanishing leading terms", []float64{2, -3, 1, 0, 0}, []float64{1, 2}},
+	}
+	for _, tt := range tests {
+		got := solvePolynomial(tt.coeffs)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got roots %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if math.Abs(got[i]-tt.want[i]) > 1e-6 {
+				t.Errorf("%s: got roots %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestTorusHit(t *testing.T) {
+	// A ring around the z axis, with its tube reaching from 0.75 to 1.25 from the axis
+	// and from -0.25 to 0.25 along it.
+	torus, err := NewTorus(NewPoint3(0, 0, 0), New(0, 0, 1), 1, 0.25, NewLambertian(NewColor(0.5, 0.5, 0.5)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rayT := NewInterval(0.001, math.Inf(1))
+
+	tests := []struct {
+		name    string
+		ray     Ray
+		hit     bool
+		t       float64
+		normal  Vec3 // Outward normal at the hit
+		tangent bool // The ray grazes the surface, so the normal may face either way
+	}{
+		{"through the hole", NewRay(NewPoint3(0, 0, 5), New(0, 0, -1)), false, 0, Vec3{}, false},
+		{"through the hole at an angle", NewRay(NewPoint3(0.3, 0.2, 5), New(-0.06, -0.04, -1)), false, 0, Vec3{}, false},
+		{"head on", NewRay(NewPoint3(-5, 0, 0), New(1, 0, 0)), true, 3.75, New(-1, 0, 0), false},
+		{"tangent to the top", NewRay(NewPoint3(-5, 0, 0.25), New(1, 0, 0)), true, 4, New(0, 0, 1), true},
+		{"tangent to the outer equator", NewRay(NewPoint3(1.25, -5, 0), New(0, 1, 0)), true, 5, New(1, 0, 0), true},
+		{"just above the top", NewRay(NewPoint3(-5, 0, 0.25+1e-6), New(1, 0, 0)), false, 0, Vec3{}, false},
+		{"starting far away", NewRay(NewPoint3(-1e6, 0, 0), New(1, 0, 0)), true, 1e6 - 1.25, New(-1, 0, 0), false},
+		{"starting inside the tube", NewRay(NewPoint3(1, 0, 0), New(0, 0, 1)), true, 0.25, New(0, 0, 1), false},
+	}
+	for _, tt := range tests {
+		hit, rec := torus.Hit(tt.ray, rayT, nil)
+		if hit != tt.hit {
+			t.Errorf("%s: hit = %v, want %v", tt.name, hit, tt.hit)
+			continue
+		}
+		if !hit {
+			continue
+		}
+		if math.Abs(rec.T()-tt.t) > 1e-6*math.Max(1, tt.t) {
+			t.Errorf("%s: t = %v, want %v", tt.name, rec.T(), tt.t)
+		}
+		if tt.tangent {
+			if math.Abs(Dot(rec.Normal(), tt.normal)) < 1-1e-6 {
+				t.Errorf("%s: normal = %v, want parallel to %v", tt.name, rec.Normal(), tt.normal)
+			}
+			continue
+		}
+
+		// The hit normal faces the ray, so it is the outward normal on a front face.
+		outward := rec.Normal()
+		if !rec.FrontFace() {
+			outward = outward.Inv()
+		}
+		if outward.Sub(tt.normal).Length() > 1e-6 {
+			t.Errorf("%s: outward normal = %v, want %v", tt.name, outward, tt.normal)
+		}
+	}
+}
+
+func TestNewTorusRejectsTubesReachingTheAxis(t *testing.T) {
+	mat := NewLambertian(NewColor(0.5, 0.5, 0.5))
+	for _, radii := range [][2]float64{{1, 1}, {1, 2}, {1, 0}, {0, 0}, {1, -0.5}} {
+		if _, err := NewTorus(NewPoint3(0, 0, 0), New(0, 0, 1), radii[0], radii[1], mat); err == nil {
+			t.Errorf("NewTorus accepted major radius %v and minor radius %v", radii[0], radii[1])
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package vec3
+
+import (
+	"errors"
+	"math"
+)
+
+// Mat4 is a 4x4 matrix in row-major order, applied to column vectors.
+type Mat4 [4][4]float64
+
+func IdentityMat4() Mat4 {
+	return Mat4{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+func (m Mat4) Mul(n Mat4) Mat4 {
+	var out Mat4
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				out[i][j] += m[i][k] * n[k][j]
+			}
+		}
+	}
+	return out
+}
+
+func (m Mat4) Transpose() Mat4 {
+	var out Mat4
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			out[i][j] = m[j][i]
+		}
+	}
+	return out
+}
+
+// Inverse returns the inverse of m, or false if m is singular.
+func (m Mat4) Inverse() (Mat4, bool) {
+	// Gauss-Jordan elimination with partial pivoting, turning m into the identity and
+	// the identity into the inverse.
+	inv := IdentityMat4()
+	for col := 0; col < 4; col++ {
+		pivot := col
+		for row := col + 1; row < 4; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if m[pivot][col] == 0 {
+			return Mat4{}, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		scale := 1 / m[col][col]
+		for j := 0; j < 4; j++ {
+			m[col][j] *= scale
+			inv[col][j] *= scale
+		}
+		for row := 0; row < 4; row++ {
+			if row == col {
+				continue
+			}
+			factor := m[row][col]
+			for j := 0; j < 4; j++ {
+				m[row][j] -= factor * m[col][j]
+				inv[row][j] -= factor * inv[col][j]
+			}
+		}
+	}
+	return inv, true
+}
+
+// Transform is an affine transformation together with its inverse, which is computed
+// once when the transform is built.
+type Transform struct {
+	m, inv Mat4
+}
+
+var ErrSingularTransform = errors.New("transform matrix is not invertible")
+
+// NewTransform returns the transform with matrix m, whose bottom row must be 0 0 0 1.
+func NewTransform(m Mat4) (Transform, error) {
+	inv, ok := m.Inverse()
+	if !ok {
+		return Transform{}, ErrSingularTransform
+	}
+	return Transform{m, inv}, nil
+}
+
+func IdentityTransform() Transform {
+	return Transform{IdentityMat4(), IdentityMat4()}
+}
+
+func Translate(offset Vec3) Transform {
+	m, inv := IdentityMat4(), IdentityMat4()
+	m[0][3], m[1][3], m[2][3] = offset.x, offset.y, offset.z
+	inv[0][3], inv[1][3], inv[2][3] = -offset.x, -offset.y, -offset.z
+	return Transform{m, inv}
+}
+
+// Scale scales by the given factor along each axis, which must not be zero.
+func Scale(factors Vec3) Transform {
+	m, inv := IdentityMat4(), IdentityMat4()
+	m[0][0], m[1][1], m[2][2] = factors.x, factors.y, factors.z
+	inv[0][0], inv[1][1], inv[2][2] = 1/factors.x, 1/factors.y, 1/factors.z
+	return Transform{m, inv}
+}
+
+// Rotate rotates counter-clockwise by the given angle in degrees around axis, looking
+// down the axis towards the origin.
+func Rotate(axis Vec3, degrees float64) Transform {
+	a := UnitVector(axis)
+	theta := DegreesToRadians(degrees)
+	sinTheta, cosTheta := math.Sin(theta), math.Cos(theta)
+
+	// Rodrigues' rotation formula.
+	m := IdentityMat4()
+	m[0][0] = a.x*a.x + (1-a.x*a.x)*cosTheta
+	m[0][1] = a.x*a.y*(1-cosTheta) - a.z*sinTheta
+	m[0][2] = a.x*a.z*(1-cosTheta) + a.y*sinTheta
+	m[1][0] = a.x*a.y*(1-cosTheta) + a.z*sinTheta
+	m[1][1] = a.y*a.y + (1-a.y*a.y)*cosTheta
+	m[1][2] = a.y*a.z*(1-cosTheta) - a.x*sinTheta
+	m[2][0] = a.x*a.z*(1-cosTheta) - a.y*sinTheta
+	m[2][1] = a.y*a.z*(1-cosTheta) + a.x*sinTheta
+	m[2][2] = a.z*a.z + (1-a.z*a.z)*cosTheta
+
+	// The inverse of a rotation is its transpose.
+	return Transform{m, m.Transpose()}
+}
+
+func RotateX(degrees float64) Transform { return Rotate(New(1, 0, 0), degrees) }
+func RotateY(degrees float64) Transform { return Rotate(New(0, 1, 0), degrees) }
+func RotateZ(degrees float64) Transform { return Rotate(New(0, 0, 1), degrees) }
+
+func (t Transform) Matrix() Mat4 { return t.m }
+
+func (t Transform) Inverse() Transform {
+	return Transform{t.inv, t.m}
+}
+
+// Then returns the transform that applies t first and next after it, so that
+// Scale(s).Then(Rotate(a, d)).Then(Translate(o)) scales, rotates and then translates.
+func (t Transform) Then(next Transform) Transform {
+	return Transform{next.m.Mul(t.m), t.inv.Mul(next.inv)}
+}
+
+func (t Transform) Point(p Point3) Point3 {
+	m := &t.m
+	return NewPoint3(
+		m[0][0]*p.x+m[0][1]*p.y+m[0][2]*p.z+m[0][3],
+		m[1][0]*p.x+m[1][1]*p.y+m[1][2]*p.z+m[1][3],
+		m[2][0]*p.x+m[2][1]*p.y+m[2][2]*p.z+m[2][3])
+}
+
+func (t Transform) Vector(v Vec3) Vec3 {
+	m := &t.m
+	return New(
+		m[0][0]*v.x+m[0][1]*v.y+m[0][2]*v.z,
+		m[1][0]*v.x+m[1][1]*v.y+m[1][2]*v.z,
+		m[2][0]*v.x+m[2][1]*v.y+m[2][2]*v.z)
+}
+
+// Normal transforms a surface normal, which takes the inverse transpose so that it
+// stays perpendicular to the transformed surface. The result is not normalized.
+func (t Transform) Normal(n Vec3) Vec3 {
+	inv := &t.inv
+	return New(
+		inv[0][0]*n.x+inv[1][0]*n.y+inv[2][0]*n.z,
+		inv[0][1]*n.x+inv[1][1]*n.y+inv[2][1]*n.z,
+		inv[0][2]*n.x+inv[1][2]*n.y+inv[2][2]*n.z)
+}
+
+// Ray transforms the origin and direction of r. The direction is not normalized, so a
+// hit at distance t along the transformed ray is at t along r as well.
+func (t Transform) Ray(r Ray) Ray {
+	return NewRayAtTime(t.Point(r.Origin()), t.Vector(r.Direction()), r.Time())
+}
+
+// Box returns the bounding box of the transformed corners of b.
+func (t Transform) Box(b AABB) AABB {
+	if !b.IsBounded() {
+		return UniverseAABB()
+	}
+	bbox := EmptyAABB()
+	for i := 0; i < 8; i++ {
+		x, y, z := b.x.min, b.y.min, b.z.min
+		if i&1 != 0 {
+			x = b.x.max
+		}
+		if i&2 != 0 {
+			y = b.y.max
+		}
+		if i&4 != 0 {
+			z = b.z.max
+		}
+		corner := t.Point(NewPoint3(x, y, z))
+		bbox = NewAABBEnclosing(bbox, NewAABBFromPoints(corner, corner))
+	}
+	return bbox
+}
+
+// Instance places a shared object in the scene through a transform from the object's
+// own space to world space. Many instances can refer to the same object, such as a
+// mesh, which is then stored only once.
+type Instance struct {
+	object    Hittable
+	transform Transform
+	bbox      AABB
+}
+
+func NewInstance(object Hittable, transform Transform) Instance {
+	return Instance{object, transform, transform.Box(object.BoundingBox())}
+}
+
+func (in Instance) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	return hitTransformed(in.object, in.transform, r, rayT, rng)
+}
+
+func (in Instance) BoundingBox() AABB { return in.bbox }
+
+// hitTransformed intersects r with an object placed in the world by transform.
+func hitTransformed(object Hittable, transform Transform, r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	// Intersect the object with the ray moved into object space.
+	hit, rec := object.Hit(transform.Inverse().Ray(r), rayT, rng)
+	if !hit {
+		return false, Hit{}
+	}
+
+	// Move the hit back to world space. The transformed normal stays on the side the
+	// ray arrived from, so the front face flag carries over.
+	rec.p = transform.Point(rec.p)
+	rec.normal = UnitVector(transform.Normal(rec.normal))
+	return true, rec
+}
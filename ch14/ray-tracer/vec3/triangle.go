@@ -0,0 +1,99 @@
+package vec3
+
+import "math"
+
+// UV is a pair of surface, or texture, coordinates.
+type UV struct {
+	U, V float64
+}
+
+type Triangle struct {
+	v0, v1, v2 Point3
+	mat        Material
+	bbox       AABB
+}
+
+// NewTriangle returns a triangle whose front face is the one its vertices wind
+// counter-clockwise around. Hits report the barycentric weights of v1 and v2 as UVs.
+func NewTriangle(v0 Point3, v1 Point3, v2 Point3, material Material) Triangle {
+	return Triangle{v0, v1, v2, material, triangleBox(v0, v1, v2)}
+}
+
+func (tri Triangle) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	ok, t, b0, b1, b2 := intersectTriangle(r, rayT, tri.v0, tri.v1, tri.v2)
+	if !ok {
+		return false, Hit{}
+	}
+
+	p := barycentric(tri.v0.Vec3, tri.v1.Vec3, tri.v2.Vec3, b0, b1, b2)
+	hitRecord := NewHit(Point3{p}, Vec3{}, t)
+	hitRecord.SetFaceNormal(r, UnitVector(Cross(tri.v1.Sub(tri.v0.Vec3), tri.v2.Sub(tri.v0.Vec3))))
+	hitRecord.SetUV(b1, b2)
+	hitRecord.SetMaterial(tri.mat)
+	return true, hitRecord
+}
+
+func (tri Triangle) BoundingBox() AABB { return tri.bbox }
+
+func intersectTriangle(r Ray, rayT Interval, v0 Point3, v1 Point3, v2 Point3) (bool, float64, float64, float64, float64) {
+	// Watertight ray/triangle intersection (Woop, Benthin and Wald, 2013). The vertices are
+	// moved into a space where the ray starts at the origin and points down +z, so rays
+	// hitting a shared edge or vertex of two triangles always hit exactly one of them.
+	// Returns the ray parameter and the barycentric weights of v0, v1 and v2.
+	dir := r.Direction()
+	kz := 0
+	if math.Abs(dir.y) > math.Abs(dir.Axis(kz)) {
+		kz = 1
+	}
+	if math.Abs(dir.z) > math.Abs(dir.Axis(kz)) {
+		kz = 2
+	}
+	kx := (kz + 1) % 3
+	ky := (kx + 1) % 3
+	if dir.Axis(kz) < 0 {
+		// Swap to preserve the winding direction of the triangle.
+		kx, ky = ky, kx
+	}
+
+	dz := dir.Axis(kz)
+	if dz == 0 {
+		return false, 0, 0, 0, 0
+	}
+	sx := dir.Axis(kx) / dz
+	sy := dir.Axis(ky) / dz
+	sz := 1 / dz
+
+	a := v0.Sub(r.Origin().Vec3)
+	b := v1.Sub(r.Origin().Vec3)
+	c := v2.Sub(r.Origin().Vec3)
+
+	ax, ay := a.Axis(kx)-sx*a.Axis(kz), a.Axis(ky)-sy*a.Axis(kz)
+	bx, by := b.Axis(kx)-sx*b.Axis(kz), b.Axis(ky)-sy*b.Axis(kz)
+	cx, cy := c.Axis(kx)-sx*c.Axis(kz), c.Axis(ky)-sy*c.Axis(kz)
+
+	// Scaled barycentric weights of v0, v1 and v2, from the edge functions.
+	u := cx*by - cy*bx
+	v := ax*cy - ay*cx
+	w := bx*ay - by*ax
+	if (u < 0 || v < 0 || w < 0) && (u > 0 || v > 0 || w > 0) {
+		return false, 0, 0, 0, 0
+	}
+	det := u + v + w
+	if det == 0 {
+		return false, 0, 0, 0, 0
+	}
+
+	t := (u*sz*a.Axis(kz) + v*sz*b.Axis(kz) + w*sz*c.Axis(kz)) / det
+	if !rayT.Surrounds(t) {
+		return false, 0, 0, 0, 0
+	}
+	return true, t, u / det, v / det, w / det
+}
+
+func barycentric(a Vec3, b Vec3, c Vec3, b0 float64, b1 float64, b2 float64) Vec3 {
+	return a.Mul(b0).Add(b.Mul(b1)).Add(c.Mul(b2))
+}
+
+func triangleBox(v0 Point3, v1 Point3, v2 Point3) AABB {
+	return NewAABBEnclosing(NewAABBFromPoints(v0, v1), NewAABBFromPoints(v0, v2))
+}
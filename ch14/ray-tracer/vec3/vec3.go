@@ -51,12 +51,12 @@ func (v Vec3) LengthSquared() float64 {
 	return v.x*v.x + v.y*v.y + v.z*v.z
 }
 
-func RandomVec3() Vec3 {
-	return New(Random(), Random(), Random())
+func RandomVec3(rng RNG) Vec3 {
+	return New(Random(rng), Random(rng), Random(rng))
 }
 
-func RandomInRangeVec3(min float64, max float64) Vec3 {
-	return New(RandomInRange(min, max), RandomInRange(min, max), RandomInRange(min, max))
+func RandomInRangeVec3(rng RNG, min float64, max float64) Vec3 {
+	return New(RandomInRange(rng, min, max), RandomInRange(rng, min, max), RandomInRange(rng, min, max))
 }
 
 func (v Vec3) Length() float64 {
@@ -78,30 +78,30 @@ func UnitVector(v Vec3) Vec3 {
 	return v.Div(v.Length())
 }
 
-func RandomInUnitDisk() Vec3 {
+func RandomInUnitDisk(rng RNG) Vec3 {
 	for {
-		p := New(RandomInRange(-1, 1), RandomInRange(-1, 1), 0)
+		p := New(RandomInRange(rng, -1, 1), RandomInRange(rng, -1, 1), 0)
 		if p.LengthSquared() < 1 {
 			return p
 		}
 	}
 }
 
-func RandomInUnitSphere() Vec3 {
+func RandomInUnitSphere(rng RNG) Vec3 {
 	for {
-		p := RandomInRangeVec3(-1, 1)
+		p := RandomInRangeVec3(rng, -1, 1)
 		if p.LengthSquared() < 1 {
 			return p
 		}
 	}
 }
 
-func RandomUnitVector() Vec3 {
-	return UnitVector(RandomInUnitSphere())
+func RandomUnitVector(rng RNG) Vec3 {
+	return UnitVector(RandomInUnitSphere(rng))
 }
 
-func RandomOnHemisphere(normal Vec3) Vec3 {
-	onUnitSphere := RandomUnitVector()
+func RandomOnHemisphere(rng RNG, normal Vec3) Vec3 {
+	onUnitSphere := RandomUnitVector(rng)
 	if Dot(onUnitSphere, normal) > 0.0 { // In the same hemisphere as the normal
 		return onUnitSphere
 	}
@@ -118,3 +118,13 @@ func Refract(uv Vec3, n Vec3, etaiOverEtat float64) Vec3 {
 	rOutParallel := n.Mul(-math.Sqrt(math.Abs(1.0 - rOutPerp.LengthSquared())))
 	return rOutPerp.Add(rOutParallel)
 }
+
+func (v Vec3) Axis(n int) float64 {
+	if n == 1 {
+		return v.y
+	}
+	if n == 2 {
+		return v.z
+	}
+	return v.x
+}
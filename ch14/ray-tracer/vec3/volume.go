@@ -0,0 +1,64 @@
+package vec3
+
+import "math"
+
+// ConstantMedium is a volume of uniform density, such as smoke or fog, filling a closed
+// boundary. Rays passing through it scatter at random distances with its phase
+// function, which is Isotropic unless given.
+type ConstantMedium struct {
+	boundary      Hittable
+	negInvDensity float64
+	phaseFunction Material
+}
+
+func NewConstantMedium(boundary Hittable, density float64, albedo Color) ConstantMedium {
+	return ConstantMedium{boundary, -1 / density, NewIsotropic(albedo)}
+}
+
+// NewConstantMediumTexture returns a medium whose albedo varies through the volume as
+// tex does at the scattering points.
+func NewConstantMediumTexture(boundary Hittable, density float64, tex Texture) ConstantMedium {
+	return ConstantMedium{boundary, -1 / density, NewIsotropicTexture(tex)}
+}
+
+// NewConstantMediumPhase returns a medium that scatters with the given material as its
+// phase function.
+func NewConstantMediumPhase(boundary Hittable, density float64, phaseFunction Material) ConstantMedium {
+	return ConstantMedium{boundary, -1 / density, phaseFunction}
+}
+
+func (cm ConstantMedium) Hit(r Ray, rayT Interval, rng RNG) (bool, Hit) {
+	// Find where the ray enters and leaves the boundary. The entry may lie behind the
+	// ray origin when the ray starts inside the volume.
+	hit1, rec1 := cm.boundary.Hit(r, Universe, rng)
+	if !hit1 {
+		return false, Hit{}
+	}
+	hit2, rec2 := cm.boundary.Hit(r, NewInterval(rec1.T()+0.0001, math.Inf(1)), rng)
+	if !hit2 {
+		return false, Hit{}
+	}
+
+	t1 := math.Max(rec1.T(), rayT.Min())
+	t2 := math.Min(rec2.T(), rayT.Max())
+	if t1 >= t2 {
+		return false, Hit{}
+	}
+	t1 = math.Max(t1, 0)
+
+	// Sample a free-flight distance.
+	rayLength := r.Direction().Length()
+	distanceInsideBoundary := (t2 - t1) * rayLength
+	hitDistance := cm.negInvDensity * math.Log(1-Random(rng))
+	if hitDistance > distanceInsideBoundary {
+		return false, Hit{}
+	}
+
+	t := t1 + hitDistance/rayLength
+	hitRecord := NewHit(r.At(t), New(1, 0, 0), t) // arbitrary
+	hitRecord.frontFace = true                    // also arbitrary
+	hitRecord.SetMaterial(cm.phaseFunction)
+	return true, hitRecord
+}
+
+func (cm ConstantMedium) BoundingBox() AABB { return cm.boundary.BoundingBox() }
@@ -0,0 +1,92 @@
+package vec3
+
+import "math"
+
+// Worley is cellular noise: one feature point is scattered in each unit cell of space,
+// and the noise is the distance to the nearest of them. The points are hashed from the
+// cell coordinates and a seed, so the same seed gives the same noise.
+type Worley struct {
+	seed uint64
+}
+
+func NewWorley(rng RNG) Worley {
+	return Worley{uint64(rng.Float64() * (1 << 53))}
+}
+
+// worleyPeriod is the period of the noise along each axis.
+const worleyPeriod = 1 << 32
+
+// maxWorleyShell bounds the search. The point in the cell of p and the one in the
+// neighbouring cell across the nearest face are within sqrt(4.25) of p, so f2 is
+// always found by the third shell.
+const maxWorleyShell = 3
+
+// Evaluate returns the distances from p to the nearest and second nearest feature
+// points, and a random ID of the cell holding the nearest one. The noise repeats every
+// 2^32 units along each axis, which keeps the cell indices in range, and is zero at
+// points that are not finite.
+func (w Worley) Evaluate(p Point3) (float64, float64, uint64) {
+	if !isFinite(p.x) || !isFinite(p.y) || !isFinite(p.z) {
+		return 0, 0, 0
+	}
+	p = NewPoint3(math.Mod(p.x, worleyPeriod), math.Mod(p.y, worleyPeriod), math.Mod(p.z, worleyPeriod))
+	i := int(math.Floor(p.x))
+	j := int(math.Floor(p.y))
+	k := int(math.Floor(p.z))
+
+	// Search shells of cells of growing size around the cell of p. A feature point may
+	// lie farther away than a point in a cell that is not adjacent, so the search only
+	// stops once every cell outside the shells is at least f2 away.
+	f1, f2 := math.Inf(1), math.Inf(1)
+	var id uint64
+	for r := 0; r <= maxWorleyShell && float64(r-1) < f2; r++ {
+		for di := -r; di <= r; di++ {
+			for dj := -r; dj <= r; dj++ {
+				for dk := -r; dk <= r; dk++ {
+					if max(absInt(di), absInt(dj), absInt(dk)) != r {
+						continue
+					}
+
+					// Skip cells that cannot hold a point nearer than the second nearest.
+					gap := New(cellGap(p.x, i+di), cellGap(p.y, j+dj), cellGap(p.z, k+dk))
+					if gap.LengthSquared() >= f2*f2 {
+						continue
+					}
+
+					h := mix64(w.seed ^ mix64(uint64(i+di)^mix64(uint64(j+dj)^mix64(uint64(k+dk)))))
+					feature := New(
+						float64(i+di)+unitFloat(h),
+						float64(j+dj)+unitFloat(mix64(h)),
+						float64(k+dk)+unitFloat(mix64(mix64(h))))
+					d := feature.Sub(p.Vec3).Length()
+					if d < f1 {
+						f1, f2, id = d, f1, h
+					} else if d < f2 {
+						f2 = d
+					}
+				}
+			}
+		}
+	}
+	return f1, f2, id
+}
+
+func isFinite(x float64) bool { return !math.IsNaN(x) && !math.IsInf(x, 0) }
+
+// cellGap returns the distance from x to the nearest point of the unit interval
+// starting at cell.
+func cellGap(x float64, cell int) float64 {
+	return math.Max(0, math.Max(float64(cell)-x, x-float64(cell+1)))
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// unitFloat maps the high bits of a hash to [0, 1).
+func unitFloat(h uint64) float64 {
+	return float64(h>>11) / (1 << 53)
+}
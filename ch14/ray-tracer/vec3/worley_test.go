@@ -0,0 +1,68 @@
+package vec3
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWorleyMatchesExhaustiveSearch(t *testing.T) {
+	w := NewWorley(NewPCG(1, 0))
+	rng := NewPCG(2, 0)
+	for n := 0; n < 10000; n++ {
+		p := NewPoint3(rng.Float64()*100-50, rng.Float64()*100-50, rng.Float64()*100-50)
+		f1, f2, _ := w.Evaluate(p)
+
+		// Look at every feature point in a block of cells well beyond the search.
+		i, j, k := int(math.Floor(p.x)), int(math.Floor(p.y)), int(math.Floor(p.z))
+		want1, want2 := math.Inf(1), math.Inf(1)
+		for di := -4; di <= 4; di++ {
+			for dj := -4; dj <= 4; dj++ {
+				for dk := -4; dk <= 4; dk++ {
+					h := mix64(w.seed ^ mix64(uint64(i+di)^mix64(uint64(j+dj)^mix64(uint64(k+dk)))))
+					feature := New(
+						float64(i+di)+unitFloat(h),
+						float64(j+dj)+unitFloat(mix64(h)),
+						float64(k+dk)+unitFloat(mix64(mix64(h))))
+					d := feature.Sub(p.Vec3).Length()
+					if d < want1 {
+						want1, want2 = d, want1
+					} else if d < want2 {
+						want2 = d
+					}
+				}
+			}
+		}
+		if f1 != want1 || f2 != want2 {
+			t.Fatalf("Evaluate(%v) = %v, %v, want %v, %v", p, f1, f2, want1, want2)
+		}
+	}
+}
+
+func TestWorleyExtremePoints(t *testing.T) {
+	w := NewWorley(NewPCG(1, 0))
+	points := []Point3{
+		NewPoint3(1e17, 0, 0),
+		NewPoint3(1e19, 0, 0),
+		NewPoint3(0, -1e300, 0),
+		NewPoint3(0, 0, math.MaxFloat64),
+		NewPoint3(math.Inf(1), 0, 0),
+		NewPoint3(0, math.Inf(-1), 0),
+		NewPoint3(0, 0, math.NaN()),
+	}
+	for _, p := range points {
+		done := make(chan [2]float64, 1)
+		go func() {
+			f1, f2, _ := w.Evaluate(p)
+			done <- [2]float64{f1, f2}
+		}()
+		select {
+		case f := <-done:
+			if math.IsNaN(f[0]) || math.IsNaN(f[1]) || f[0] > f[1] || f[1] > 3 {
+				t.Errorf("Evaluate(%v) = %v, %v", p, f[0], f[1])
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Evaluate(%v) did not return", p)
+		}
+	}
+}